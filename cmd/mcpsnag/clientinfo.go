@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func clientProfile(preset, configFile, capabilities string, identity protocol.Implementation) (protocol.ClientProfile, error) {
+	profile, _ := protocol.ClientPreset("mcpsnag")
+	if preset != "" {
+		var ok bool
+		if profile, ok = protocol.ClientPreset(preset); !ok {
+			return profile, fmt.Errorf("unknown --client-preset %q (available: %s)", preset, strings.Join(protocol.ClientPresetNames(), ", "))
+		}
+	}
+
+	if configFile != "" {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return profile, fmt.Errorf("cannot read --client-config: %w", err)
+		}
+		var config struct {
+			Capabilities *protocol.ClientCapabilities `json:"capabilities"`
+			ClientInfo   *protocol.Implementation     `json:"clientInfo"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return profile, fmt.Errorf("invalid --client-config %s: %w", configFile, err)
+		}
+		if config.Capabilities != nil {
+			profile.Capabilities = *config.Capabilities
+		}
+		if config.ClientInfo != nil {
+			overrideIdentity(&profile.ClientInfo, *config.ClientInfo)
+		}
+	}
+
+	if capabilities != "" {
+		var caps protocol.ClientCapabilities
+		if err := json.Unmarshal([]byte(capabilities), &caps); err != nil {
+			return profile, fmt.Errorf("invalid --client-capabilities: %w", err)
+		}
+		profile.Capabilities = caps
+	}
+
+	overrideIdentity(&profile.ClientInfo, identity)
+	return profile, nil
+}
+
+func overrideIdentity(info *protocol.Implementation, override protocol.Implementation) {
+	if override.Name != "" {
+		info.Name = override.Name
+	}
+	if override.Title != "" {
+		info.Title = override.Title
+	}
+	if override.Version != "" {
+		info.Version = override.Version
+	}
+}
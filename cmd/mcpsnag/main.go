@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/bigbag/mcpsnag/internal/client"
@@ -13,13 +17,38 @@ import (
 	"github.com/bigbag/mcpsnag/internal/protocol"
 )
 
-type headerFlags []string
+const stdioScheme = "stdio://"
 
-func (h *headerFlags) String() string {
+const (
+	exitTerminateFailed      = 1
+	exitTerminateUnsupported = 2
+	exitCancelAfter          = 124
+	exitInterrupted          = 130
+)
+
+var (
+	errInterrupted = errors.New("interrupted by user")
+	errCancelAfter = errors.New("cancel-after deadline reached")
+)
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case errors.Is(err, errCancelAfter):
+		return exitCancelAfter
+	default:
+		return 1
+	}
+}
+
+type repeatableFlag []string
+
+func (h *repeatableFlag) String() string {
 	return strings.Join(*h, ", ")
 }
 
-func (h *headerFlags) Set(value string) error {
+func (h *repeatableFlag) Set(value string) error {
 	*h = append(*h, value)
 	return nil
 }
@@ -28,7 +57,39 @@ var flagsWithValues = map[string]bool{
 	"-d": true, "--data": true, "-data": true,
 	"-H": true, "--header": true, "-header": true,
 	"--session": true, "-session": true,
+	"--protocol-version": true, "-protocol-version": true,
+	"--client-preset": true, "-client-preset": true,
+	"--client-config": true, "-client-config": true,
+	"--client-capabilities": true, "-client-capabilities": true,
+	"--client-name": true, "-client-name": true,
+	"--client-title": true, "-client-title": true,
+	"--client-version": true, "-client-version": true,
 	"--timeout": true, "-timeout": true,
+	"--root": true, "-root": true,
+	"--count": true, "-count": true,
+	"--interval": true, "-interval": true,
+	"--log-level": true, "-log-level": true,
+	"--log-file": true, "-log-file": true,
+	"--complete": true, "-complete": true,
+	"--complete-arg": true, "-complete-arg": true,
+	"--complete-context": true, "-complete-context": true,
+	"--max-pages": true, "-max-pages": true,
+	"--max-reconnects": true, "-max-reconnects": true,
+	"--max-event-size": true, "-max-event-size": true,
+	"--transport": true, "-transport": true,
+	"--unix-socket": true, "-unix-socket": true,
+	"--retry": true, "-retry": true,
+	"--cert": true, "-cert": true,
+	"--proxy": true, "-proxy": true, "-x": true,
+	"--noproxy": true, "-noproxy": true,
+	"--resolve": true, "-resolve": true,
+	"--connect-to": true, "-connect-to": true,
+	"--key": true, "-key": true,
+	"--cacert": true, "-cacert": true,
+	"--tls-min-version": true, "-tls-min-version": true,
+	"--tls-server-name": true, "-tls-server-name": true,
+	"--retry-max-time": true, "-retry-max-time": true,
+	"--cancel-after": true, "-cancel-after": true,
 }
 
 func reorderArgs(args []string) []string {
@@ -42,6 +103,10 @@ func reorderArgs(args []string) []string {
 	i := 1
 	for i < len(args) {
 		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
 		if strings.HasPrefix(arg, "-") {
 			if strings.Contains(arg, "=") {
 				flags = append(flags, arg)
@@ -61,66 +126,248 @@ func reorderArgs(args []string) []string {
 
 	result := []string{args[0]}
 	result = append(result, flags...)
+	result = append(result, "--")
 	result = append(result, positional...)
 	return result
 }
 
 func main() {
 	var (
-		data     string
-		headers  headerFlags
-		raw      bool
-		session  string
-		initOnly bool
-		compact  bool
-		noStream bool
-		verbose  bool
-		timeout  time.Duration
+		data      string
+		headers   repeatableFlag
+		raw       bool
+		session   string
+		initOnly  bool
+		compact   bool
+		noStream  bool
+		verbose   bool
+		timeout   time.Duration
+		stdio     bool
+		listen    bool
+		rootArgs  repeatableFlag
+		ping      bool
+		count     int
+		interval  time.Duration
+		complete  string
+		compArg   string
+		compCtx   repeatableFlag
+		terminate bool
+		reconnect int
+		maxEvent  int
+		httpMode  string
+		unixSock  string
+		retries   int
+		retryTime time.Duration
+		retryAll  bool
+		tlsOpts   client.TLSOptions
+		netOpts   client.NetworkOptions
+		resolve   repeatableFlag
+		connectTo repeatableFlag
+		reinit    bool
+		version   string
+		preset    string
+		clientCfg string
+		clientCap string
+		identity  protocol.Implementation
+		progress  bool
+		logLevel  string
+		logFile   string
+		structOut bool
+		noValid   bool
+		follow    bool
+		allPages  bool
+		maxPages  int
+		cancelAt  time.Duration
 	)
 
-	flag.StringVar(&data, "d", "", "JSON body (method + params)")
-	flag.StringVar(&data, "data", "", "JSON body (method + params)")
+	flag.StringVar(&data, "d", "", "JSON body (method + params, or an array of them for a batch)")
+	flag.StringVar(&data, "data", "", "JSON body (method + params, or an array of them for a batch)")
 	flag.Var(&headers, "H", "HTTP header (repeatable)")
 	flag.Var(&headers, "header", "HTTP header (repeatable)")
 	flag.BoolVar(&raw, "raw", false, "Skip auto-initialization")
 	flag.StringVar(&session, "session", "", "Use existing session ID")
+	flag.BoolVar(&reinit, "reinit-on-expiry", false, "Start a new session and replay the request when the server reports the session expired (HTTP 404)")
+	flag.StringVar(&version, "protocol-version", "", "MCP protocol version to request: "+strings.Join(protocol.SupportedVersions, ", ")+" (default "+protocol.MCPVersion+")")
+	flag.StringVar(&preset, "client-preset", "", "Declare the capabilities and clientInfo of a known host: "+strings.Join(protocol.ClientPresetNames(), ", "))
+	flag.StringVar(&clientCfg, "client-config", "", "JSON file with \"capabilities\" and/or \"clientInfo\" to send in initialize")
+	flag.StringVar(&clientCap, "client-capabilities", "", "Client capabilities to declare in initialize, as a JSON object")
+	flag.StringVar(&identity.Name, "client-name", "", "Override the clientInfo name sent in initialize")
+	flag.StringVar(&identity.Title, "client-title", "", "Override the clientInfo title sent in initialize")
+	flag.StringVar(&identity.Version, "client-version", "", "Override the clientInfo version sent in initialize")
 	flag.BoolVar(&initOnly, "init-only", false, "Only initialize, print session")
 	flag.BoolVar(&compact, "c", false, "Compact JSON output")
 	flag.BoolVar(&compact, "compact", false, "Compact JSON output")
 	flag.BoolVar(&noStream, "no-stream", false, "Wait for full response")
+	flag.BoolVar(&progress, "progress", false, "Request progress notifications and show them on stderr")
+	flag.StringVar(&logLevel, "log-level", "", "Ask the server to send logs at this level and above: "+strings.Join(protocol.LogLevels, ", "))
+	flag.StringVar(&logFile, "log-file", "", "Append server log messages to this file instead of stderr")
+	flag.BoolVar(&structOut, "structured", false, "For tools/call, print only structuredContent")
+	flag.BoolVar(&noValid, "no-validate", false, "For tools/call, skip the tools/list lookup that validates structuredContent against the tool's outputSchema")
+	flag.BoolVar(&follow, "follow-links", false, "For tools/call, read resource_link content with resources/read and embed the result")
+	flag.BoolVar(&allPages, "all-pages", false, "Follow nextCursor for */list methods and merge every page into one result")
+	flag.IntVar(&maxPages, "max-pages", 100, "Stop after this many pages with --all-pages or when looking up a tool's outputSchema (0 means no limit)")
 	flag.BoolVar(&verbose, "v", false, "Show request/response details")
 	flag.BoolVar(&verbose, "verbose", false, "Show request/response details")
 	flag.DurationVar(&timeout, "timeout", 30*time.Second, "Request timeout")
+	flag.DurationVar(&cancelAt, "cancel-after", 0, "Cancel the request with notifications/cancelled after this duration")
+	flag.StringVar(&httpMode, "transport", client.HTTPModeAuto, "HTTP transport: auto, streamable or sse (legacy HTTP+SSE)")
+	flag.IntVar(&retries, "retry", 0, "Retry idempotent requests this many times on connection errors and HTTP 408/429/5xx")
+	flag.DurationVar(&retryTime, "retry-max-time", 0, "Give up retrying after this total time (0 means no limit)")
+	flag.BoolVar(&retryAll, "retry-all", false, "With --retry, also retry requests that are not idempotent")
+	flag.StringVar(&tlsOpts.CertFile, "cert", "", "Client certificate file (PEM) for mutual TLS")
+	flag.StringVar(&tlsOpts.KeyFile, "key", "", "Private key file (PEM) for --cert")
+	flag.StringVar(&tlsOpts.CAFile, "cacert", "", "CA bundle (PEM) to verify the server instead of the system roots")
+	flag.BoolVar(&tlsOpts.Insecure, "k", false, "Skip TLS certificate verification")
+	flag.BoolVar(&tlsOpts.Insecure, "insecure", false, "Skip TLS certificate verification")
+	flag.StringVar(&tlsOpts.MinVersion, "tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	flag.StringVar(&tlsOpts.ServerName, "tls-server-name", "", "Override the TLS server name (SNI) used for the handshake and verification")
+	flag.StringVar(&netOpts.Proxy, "x", "", "Use this proxy: http://, https://, socks5:// or socks5h:// URL")
+	flag.StringVar(&netOpts.Proxy, "proxy", "", "Use this proxy: http://, https://, socks5:// or socks5h:// URL")
+	flag.StringVar(&netOpts.NoProxy, "noproxy", "", "Comma-separated hosts, domains or CIDRs that bypass the proxy (* for all)")
+	flag.Var(&resolve, "resolve", "Resolve host:port to this address, as host:port:addr (repeatable)")
+	flag.Var(&connectTo, "connect-to", "Connect to another host:port instead, as host:port:connect-host:connect-port (repeatable)")
+	flag.StringVar(&unixSock, "unix-socket", "", "Connect through this Unix domain socket instead of TCP")
+	flag.IntVar(&reconnect, "max-reconnects", 3, "Max SSE reconnects with Last-Event-ID when a stream drops (0 disables)")
+	flag.IntVar(&maxEvent, "max-event-size", client.DefaultMaxEventSize, "Max size in bytes of a single SSE event or WebSocket message")
+	flag.StringVar(&complete, "complete", "", "Request completions for a prompt name or resource template URI (with --complete-arg)")
+	flag.StringVar(&compArg, "complete-arg", "", "Argument to complete, as name=partial-value")
+	flag.Var(&compCtx, "complete-context", "Already resolved argument passed as completion context, as name=value (repeatable)")
+	flag.BoolVar(&listen, "listen", false, "Print server-initiated messages until interrupted; read roots commands from stdin")
+	flag.Var(&rootArgs, "root", "Directory or file:// URI served to roots/list (repeatable)")
+	flag.BoolVar(&ping, "ping", false, "Send MCP ping requests and print round-trip times and statistics")
+	flag.IntVar(&count, "count", 0, "With --ping, stop after this many pings (0 means until interrupted)")
+	flag.DurationVar(&interval, "interval", time.Second, "With --ping, wait this long between pings")
+	flag.BoolVar(&terminate, "terminate", false, "Terminate the session when done (with --session and no -d, only terminate)")
+	flag.BoolVar(&stdio, "stdio", false, "Launch the server command given after -- and talk over stdin/stdout")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: mcpsnag [options] <url>\n\n")
-		fmt.Fprintf(os.Stderr, "A curl-like CLI for testing MCP servers over HTTP.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: mcpsnag [options] <url>\n")
+		fmt.Fprintf(os.Stderr, "       mcpsnag [options] --stdio -- <command> [args...]\n\n")
+		fmt.Fprintf(os.Stderr, "A curl-like CLI for testing MCP servers over HTTP, WebSocket and stdio.\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp -d '{\"method\":\"tools/list\"}'\n")
 		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp -H \"Authorization: Bearer token\" -d '{\"method\":\"tools/list\"}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp -d '[{\"method\":\"tools/list\"},{\"method\":\"prompts/list\"}]'\n")
 		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --init-only\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --client-preset vscode -d '{\"method\":\"tools/list\"}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --listen\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --root ./src --root /data --listen\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --ping --count 5 --interval 500ms\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --log-level debug -d '{\"method\":\"tools/call\",\"params\":{\"name\":\"query\"}}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --complete code_review --complete-arg language=py\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/mcp --session <id> --terminate\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag http://localhost:3000/sse --transport sse -d '{\"method\":\"tools/list\"}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag ws://localhost:3000/mcp -d '{\"method\":\"tools/list\"}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag unix:///run/mcp.sock:/mcp -d '{\"method\":\"tools/list\"}'\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag --stdio -d '{\"method\":\"tools/list\"}' -- node server.js\n")
+		fmt.Fprintf(os.Stderr, "  mcpsnag 'stdio://node server.js' -d '{\"method\":\"tools/list\"}'\n")
 	}
 
 	os.Args = reorderArgs(os.Args)
 	flag.Parse()
 
 	if flag.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "error: URL is required")
+		if stdio {
+			fmt.Fprintln(os.Stderr, "error: server command is required with --stdio")
+		} else {
+			fmt.Fprintln(os.Stderr, "error: URL is required")
+		}
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	url := flag.Arg(0)
+	target := url
+	var command []string
+	if stdio {
+		command = flag.Args()
+		target = strings.Join(command, " ")
+	} else if strings.HasPrefix(url, stdioScheme) {
+		command = strings.Fields(strings.TrimPrefix(url, stdioScheme))
+		target = strings.Join(command, " ")
+		if len(command) == 0 {
+			fmt.Fprintln(os.Stderr, "error: stdio:// URL must include a command")
+			os.Exit(1)
+		}
+	}
+
+	netOpts.Resolve, netOpts.ConnectTo = resolve, connectTo
+
 	printer := output.NewPrinter(os.Stdout, os.Stderr, compact, verbose)
 
-	if !initOnly && data == "" {
-		fmt.Fprintln(os.Stderr, "error: -d/--data is required (or use --init-only)")
+	terminateOnly := terminate && session != "" && !initOnly && !listen && !ping && complete == "" && data == ""
+	if !initOnly && !listen && !ping && !terminateOnly && complete == "" && data == "" {
+		fmt.Fprintln(os.Stderr, "error: -d/--data is required (or use --init-only, --listen, --ping, --complete or --session with --terminate)")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if count < 0 || interval <= 0 {
+		fmt.Fprintln(os.Stderr, "error: --count must not be negative and --interval must be positive")
+		os.Exit(1)
+	}
+	if logLevel != "" && !protocol.IsLogLevel(logLevel) {
+		fmt.Fprintf(os.Stderr, "error: invalid --log-level %q (expected one of: %s)\n", logLevel, strings.Join(protocol.LogLevels, ", "))
+		os.Exit(1)
+	}
+	if logLevel != "" && raw {
+		fmt.Fprintln(os.Stderr, "error: --log-level cannot be used with --raw (logging/setLevel needs an initialized session)")
+		os.Exit(1)
+	}
+	var logOut *os.File
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			printer.PrintError(fmt.Errorf("cannot open log file: %w", err))
+			os.Exit(1)
+		}
+		printer.SetLogOutput(f)
+		logOut = f
+	}
+
+	profile, err := clientProfile(preset, clientCfg, clientCap, identity)
+	if err != nil {
+		printer.PrintError(err)
+		os.Exit(1)
+	}
+	roots, err := parseRoots(rootArgs)
+	if err != nil {
+		printer.PrintError(err)
+		os.Exit(1)
+	}
+	if len(roots) > 0 && profile.Capabilities.Roots == nil {
+		printer.PrintError(fmt.Errorf("--root: %w; declare them with --client-capabilities or --client-preset", client.ErrRootsNotDeclared))
+		os.Exit(1)
+	}
+
+	var completeParams protocol.CompleteParams
+	if complete != "" {
+		if compArg == "" {
+			fmt.Fprintln(os.Stderr, "error: --complete-arg is required with --complete")
+			os.Exit(1)
+		}
+		completeParams.Ref = protocol.ParseCompleteReference(complete)
+		name, value, ok := strings.Cut(compArg, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "error: invalid --complete-arg %q (expected name=partial-value)\n", compArg)
+			os.Exit(1)
+		}
+		completeParams.Argument.Name, completeParams.Argument.Value = name, value
+		for _, kv := range compCtx {
+			name, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "error: invalid --complete-context %q (expected name=value)\n", kv)
+				os.Exit(1)
+			}
+			if completeParams.Context == nil {
+				completeParams.Context = &protocol.CompleteContext{Arguments: make(map[string]string)}
+			}
+			completeParams.Context.Arguments[name] = value
+		}
+	}
+
 	headerMap := make(map[string]string)
 	for _, h := range headers {
 		parts := strings.SplitN(h, ":", 2)
@@ -131,45 +378,251 @@ func main() {
 		headerMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 	}
 
-	c := client.New(client.Options{
+	if command != nil {
+		printer.PrintVerbose("* Starting %s", strings.Join(command, " "))
+	}
+
+	c, err := client.New(client.Options{
 		Endpoint:  url,
 		Headers:   headerMap,
 		SessionID: session,
 		Timeout:   timeout,
 		Stream:    !noStream,
+		Command:   command,
+		Stderr:    os.Stderr,
+
+		UnixSocket:      unixSock,
+		TLS:             tlsOpts,
+		Network:         netOpts,
+		HTTPMode:        httpMode,
+		MaxReconnects:   reconnect,
+		MaxEventSize:    maxEvent,
+		ProtocolVersion: version,
+		Capabilities:    &profile.Capabilities,
+		ClientInfo:      &profile.ClientInfo,
+		Roots:           roots,
+		Retry: client.RetryPolicy{
+			MaxRetries: retries,
+			MaxTime:    retryTime,
+			RetryAll:   retryAll,
+		},
+		Logger: printer.PrintVerbose,
+
+		ReinitOnExpiry: reinit,
+		OnSessionRenewed: func(expiredID, newID string) {
+			fmt.Fprintf(os.Stderr, "warning: session %s expired; new session ID: %s\n", expiredID, newID)
+		},
+		OnLogMessage: func(msg protocol.LoggingMessageParams) {
+			printer.PrintLog(time.Now(), msg.Level, msg.Logger, msg.Data)
+		},
 	})
+	if err != nil {
+		printer.PrintError(err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		<-sigs
+		signal.Stop(sigs)
+		cancel(errInterrupted)
+	}()
 
-	if raw {
-		runRaw(c, printer, data)
-		return
+	var code int
+	if terminateOnly {
+		code = runTerminate(c, printer)
+	} else {
+		code = run(ctx, c, printer, runOptions{
+			data:        data,
+			target:      target,
+			raw:         raw,
+			initialize:  session == "",
+			initOnly:    initOnly,
+			listen:      listen,
+			ping:        ping,
+			count:       count,
+			interval:    interval,
+			logLevel:    logLevel,
+			complete:    completeParams,
+			progress:    progress,
+			structured:  structOut,
+			noValidate:  noValid,
+			followLinks: follow,
+			allPages:    allPages,
+			maxPages:    maxPages,
+			cancelAfter: cancelAt,
+		})
+		if terminate && c.Session().IsValid() {
+			if tc := runTerminate(c, printer); code == 0 && tc == exitTerminateFailed {
+				code = tc
+			}
+		}
 	}
 
-	if session == "" {
+	if err := c.Close(); err != nil {
+		printer.PrintVerbose("* Close failed: %v", err)
+	}
+	if logOut != nil {
+		if err := logOut.Close(); err != nil {
+			printer.PrintError(fmt.Errorf("cannot write log file: %w", err))
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+	os.Exit(code)
+}
+
+type runOptions struct {
+	data        string
+	target      string
+	raw         bool
+	initialize  bool
+	initOnly    bool
+	listen      bool
+	ping        bool
+	count       int
+	interval    time.Duration
+	logLevel    string
+	complete    protocol.CompleteParams
+	progress    bool
+	structured  bool
+	noValidate  bool
+	followLinks bool
+	allPages    bool
+	maxPages    int
+	cancelAfter time.Duration
+}
+
+func run(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	if opts.raw {
+		if opts.ping {
+			return runPing(ctx, c, printer, opts)
+		}
+		if opts.complete.Ref.Type != "" {
+			return runComplete(ctx, c, printer, opts)
+		}
+		return runRaw(ctx, c, printer, opts)
+	}
+
+	if opts.initialize {
 		printer.PrintVerbose("* Initializing MCP session...")
-		result, err := c.Initialize()
+		result, err := c.Initialize(ctx)
 		if err != nil {
 			printer.PrintError(fmt.Errorf("initialization failed: %w", err))
-			os.Exit(1)
+			return exitCode(err)
 		}
 		printer.PrintVerbose("* Connected to %s %s", result.ServerInfo.Name, result.ServerInfo.Version)
-		printer.PrintVerbose("* Session ID: %s", c.Session().ID)
+		if c.Session().IsValid() {
+			printer.PrintVerbose("* Session ID: %s", c.Session().ID)
+		}
+	}
+
+	if opts.logLevel != "" {
+		err := c.SetLogLevel(ctx, opts.logLevel)
+		if errors.Is(err, client.ErrLoggingNotSupported) {
+			fmt.Fprintf(os.Stderr, "warning: %v; --log-level %s not sent\n", err, opts.logLevel)
+		} else if err != nil {
+			printer.PrintError(fmt.Errorf("logging/setLevel failed: %w", err))
+			return exitCode(err)
+		}
 	}
 
-	if initOnly {
+	if opts.initOnly {
 		printer.PrintSessionInfo(c.Session().ID)
-		return
+		return 0
+	}
+
+	if opts.listen {
+		return runListen(ctx, c, printer)
+	}
+
+	if opts.ping {
+		return runPing(ctx, c, printer, opts)
+	}
+
+	if opts.complete.Ref.Type != "" {
+		return runComplete(ctx, c, printer, opts)
+	}
+
+	return runRequest(ctx, c, printer, opts)
+}
+
+func withCancelAfter(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeoutCause(ctx, d, fmt.Errorf("%w (%s)", errCancelAfter, d))
+}
 
-	runRequest(c, printer, data)
+func runTerminate(c *client.Client, printer *output.Printer) int {
+	sessionID := c.Session().ID
+	err := c.Terminate()
+	if errors.Is(err, client.ErrTerminationNotSupported) {
+		fmt.Fprintf(os.Stderr, "warning: %v; session %s left open\n", err, sessionID)
+		return exitTerminateUnsupported
+	}
+	if err != nil {
+		printer.PrintError(fmt.Errorf("session termination failed: %w", err))
+		return exitTerminateFailed
+	}
+
+	printer.PrintVerbose("* Session %s terminated", sessionID)
+	return 0
+}
+
+func runListen(ctx context.Context, c *client.Client, printer *output.Printer) int {
+	printer.PrintVerbose("* Listening for server messages (Ctrl-C to stop)...")
+	printer.PrintVerbose("* Change roots by typing: %s", rootsUsage)
+	go watchRootCommands(ctx, c, printer, os.Stdin)
+
+	err := c.Listen(ctx, func(msg json.RawMessage) error {
+		return printer.PrintRawJSON(msg)
+	})
+	if err != nil {
+		printer.PrintError(fmt.Errorf("listen failed: %w", err))
+		return 1
+	}
+
+	if ctx.Err() == nil {
+		printer.PrintVerbose("* Stream closed by server")
+	}
+	return 0
+}
+
+func isBatch(data string) bool {
+	return strings.HasPrefix(strings.TrimSpace(data), "[")
+}
+
+func runComplete(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	ctx, cancel := withCancelAfter(ctx, opts.cancelAfter)
+	defer cancel()
+
+	completion, err := c.Complete(ctx, opts.complete)
+	if err != nil {
+		printer.PrintError(fmt.Errorf("completion failed: %w", err))
+		return exitCode(err)
+	}
+	printer.PrintJSON(completion)
+	return 0
 }
 
-func runRaw(c *client.Client, printer *output.Printer, data string) {
-	resp, sessionID, err := c.RawRequest([]byte(data), func(r protocol.Response) error {
-		return printer.PrintRawJSON(r.Result)
+func runRaw(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	ctx, cancel := withCancelAfter(ctx, opts.cancelAfter)
+	defer cancel()
+
+	if isBatch(opts.data) {
+		return runRawBatch(ctx, c, printer, opts)
+	}
+
+	resp, sessionID, err := c.RawRequest(ctx, []byte(opts.data), func(msg protocol.Message) error {
+		return printer.PrintEvent(msg)
 	})
 	if err != nil {
 		printer.PrintError(err)
-		os.Exit(1)
+		return exitCode(err)
 	}
 
 	if sessionID != "" {
@@ -179,42 +632,171 @@ func runRaw(c *client.Client, printer *output.Printer, data string) {
 	if resp != nil {
 		if resp.Error != nil {
 			printer.PrintJSON(resp.Error)
-			os.Exit(1)
+			return 1
 		}
 		if resp.Result != nil {
 			printer.PrintRawJSON(resp.Result)
 		}
 	}
+	return 0
 }
 
-func runRequest(c *client.Client, printer *output.Printer, data string) {
+func runRawBatch(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	responses, sessionID, err := c.RawBatch(ctx, []byte(opts.data), func(msg protocol.Message) error {
+		return printer.PrintEvent(msg)
+	})
+	if err != nil {
+		printer.PrintError(err)
+		return exitCode(err)
+	}
+
+	if sessionID != "" {
+		printer.PrintVerbose("* Session ID: %s", sessionID)
+	}
+
+	code := 0
+	received := []*protocol.Response{}
+	for _, resp := range responses {
+		if resp == nil {
+			code = 1
+			continue
+		}
+		if resp.Error != nil {
+			code = 1
+		}
+		received = append(received, resp)
+	}
+	if len(received) < len(responses) {
+		printer.PrintError(fmt.Errorf("%d of %d batch requests got no response", len(responses)-len(received), len(responses)))
+	}
+	if len(responses) > 0 {
+		printer.PrintJSON(received)
+	}
+	return code
+}
+
+type batchItem struct {
+	ID     any             `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *protocol.Error `json:"error,omitempty"`
+}
+
+func runBatch(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	var calls []protocol.UserRequest
+	if err := json.Unmarshal([]byte(opts.data), &calls); err != nil {
+		printer.PrintError(fmt.Errorf("invalid JSON: %w", err))
+		return 1
+	}
+
+	ctx, cancel := withCancelAfter(ctx, opts.cancelAfter)
+	defer cancel()
+
+	results, err := c.Batch(ctx, calls, func(msg protocol.Message) error {
+		return printer.PrintEvent(msg)
+	})
+	if err != nil {
+		printer.PrintError(err)
+		return exitCode(err)
+	}
+
+	code := 0
+	items := make([]batchItem, len(results))
+	for i, r := range results {
+		items[i] = batchItem{ID: r.ID, Method: r.Method}
+		switch {
+		case r.Response == nil:
+			items[i].Error = &protocol.Error{Code: protocol.CodeInternalError, Message: "no response from server"}
+		case r.Response.Error != nil:
+			items[i].Error = r.Response.Error
+		default:
+			items[i].Result = r.Response.Result
+		}
+		if items[i].Error != nil {
+			printer.PrintError(fmt.Errorf("%s (id %v): %s", r.Method, r.ID, items[i].Error.Message))
+			code = 1
+		}
+	}
+
+	if len(items) > 0 {
+		printer.PrintJSON(items)
+	}
+	return code
+}
+
+func runRequest(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	if isBatch(opts.data) {
+		return runBatch(ctx, c, printer, opts)
+	}
+
 	var userReq protocol.UserRequest
-	if err := json.Unmarshal([]byte(data), &userReq); err != nil {
+	if err := json.Unmarshal([]byte(opts.data), &userReq); err != nil {
 		printer.PrintError(fmt.Errorf("invalid JSON: %w", err))
-		os.Exit(1)
+		return 1
 	}
 
 	if userReq.Method == "" {
 		printer.PrintError(fmt.Errorf("missing 'method' field in request"))
-		os.Exit(1)
+		return 1
+	}
+	if opts.structured && userReq.Method != "tools/call" {
+		printer.PrintError(fmt.Errorf("--structured only applies to tools/call"))
+		return 1
+	}
+	if opts.allPages && !strings.HasSuffix(userReq.Method, "/list") {
+		printer.PrintError(fmt.Errorf("--all-pages only applies to paginated */list methods"))
+		return 1
+	}
+
+	var reqOpts []client.RequestOption
+	if opts.progress {
+		reqOpts = append(reqOpts, client.WithProgress(func(p protocol.ProgressParams) {
+			printer.PrintProgress(p.ProgressToken, p.Progress, p.Total, p.Message)
+		}))
+	}
+
+	ctx, cancel := withCancelAfter(ctx, opts.cancelAfter)
+	defer cancel()
+
+	onEvent := func(msg protocol.Message) error {
+		return printer.PrintEvent(msg)
 	}
 
-	resp, err := c.Request(userReq.Method, userReq.Params, func(r protocol.Response) error {
-		if r.Result != nil {
-			return printer.PrintRawJSON(r.Result)
+	var resp *protocol.Response
+	var err error
+	if opts.allPages {
+		resp, err = c.RequestAllPages(ctx, userReq.Method, userReq.Params, opts.maxPages, onEvent, reqOpts...)
+		if errors.Is(err, client.ErrMaxPages) {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			err = nil
 		}
-		return nil
-	})
+	} else {
+		resp, err = c.Request(ctx, userReq.Method, userReq.Params, onEvent, reqOpts...)
+	}
+	printer.EndProgress()
 	if err != nil {
 		if resp != nil && resp.Error != nil {
 			printer.PrintJSON(resp.Error)
 		} else {
 			printer.PrintError(err)
 		}
-		os.Exit(1)
+		return exitCode(err)
+	}
+
+	if resp == nil || resp.Result == nil {
+		return 0
 	}
 
-	if resp != nil && resp.Result != nil {
-		printer.PrintRawJSON(resp.Result)
+	result := resp.Result
+	if userReq.Method == "tools/call" {
+		result, err = processToolResult(ctx, c, printer, userReq.Params, result, opts)
+	}
+	if result != nil {
+		printer.PrintRawJSON(result)
+	}
+	if err != nil {
+		printer.PrintError(err)
+		return 1
 	}
+	return 0
 }
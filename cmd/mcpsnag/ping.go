@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/client"
+	"github.com/bigbag/mcpsnag/internal/output"
+)
+
+func runPing(ctx context.Context, c *client.Client, printer *output.Printer, opts runOptions) int {
+	header := opts.target
+	if info := c.Session().ServerInfo; info != nil {
+		header += fmt.Sprintf(" (%s %s)", info.Name, info.Version)
+	}
+	printer.PrintText("PING %s", header)
+
+	var stats client.PingStats
+	start := time.Now()
+	for seq := 1; opts.count == 0 || seq <= opts.count; seq++ {
+		if seq > 1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.interval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		rtt, err := c.Ping(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		stats.Add(rtt, err)
+		if err != nil {
+			printer.PrintText("ping seq=%d error: %v", seq, err)
+		} else {
+			printer.PrintText("reply seq=%d time=%.3f ms", seq, millis(rtt))
+		}
+	}
+
+	printer.PrintText("")
+	printer.PrintText("--- %s ping statistics ---", opts.target)
+	printer.PrintText("%d pings sent, %d received, %d failed, %.1f%% loss, time %s",
+		stats.Sent, stats.Received(), stats.Failed(), stats.Loss(), time.Since(start).Round(time.Millisecond))
+	if stats.Received() > 0 {
+		printer.PrintText("rtt min/avg/max/stddev = %.3f/%.3f/%.3f/%.3f ms",
+			millis(stats.Min()), millis(stats.Avg()), millis(stats.Max()), millis(stats.StdDev()))
+		return 0
+	}
+
+	if stats.Sent == 0 && ctx.Err() != nil {
+		return exitCode(context.Cause(ctx))
+	}
+	return 1
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
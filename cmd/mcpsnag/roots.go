@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bigbag/mcpsnag/internal/client"
+	"github.com/bigbag/mcpsnag/internal/output"
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+const rootsUsage = "roots [list | add <path>... | remove <path>... | set <path>... | clear]"
+
+func parseRoots(args []string) ([]protocol.Root, error) {
+	var roots []protocol.Root
+	for _, arg := range args {
+		root, err := client.NewRoot(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(roots, func(r protocol.Root) bool { return r.URI == root.URI }) {
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
+
+func watchRootCommands(ctx context.Context, c *client.Client, printer *output.Printer, in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := runRootCommand(ctx, c, printer, line); err != nil {
+			printer.PrintError(err)
+		}
+	}
+}
+
+func runRootCommand(ctx context.Context, c *client.Client, printer *output.Printer, line string) error {
+	fields := strings.Fields(line)
+	if fields[0] != "roots" {
+		return fmt.Errorf("unknown command %q (usage: %s)", fields[0], rootsUsage)
+	}
+	action, args := "list", fields[1:]
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	roots := c.Roots()
+	changed, err := parseRoots(args)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "list":
+		return printer.PrintEvent(protocol.ListRootsResult{Roots: roots})
+	case "add":
+		if len(changed) == 0 {
+			return errors.New("usage: roots add <path>...")
+		}
+		for _, root := range changed {
+			if !slices.ContainsFunc(roots, func(r protocol.Root) bool { return r.URI == root.URI }) {
+				roots = append(roots, root)
+			}
+		}
+	case "remove":
+		if len(changed) == 0 {
+			return errors.New("usage: roots remove <path>...")
+		}
+		roots = slices.DeleteFunc(roots, func(r protocol.Root) bool {
+			return slices.ContainsFunc(changed, func(cr protocol.Root) bool { return cr.URI == r.URI })
+		})
+	case "set":
+		roots = changed
+	case "clear":
+		roots = nil
+	default:
+		return fmt.Errorf("unknown roots action %q (usage: %s)", action, rootsUsage)
+	}
+
+	caps := c.ClientCapabilities()
+	if caps.Roots == nil {
+		return fmt.Errorf("%w; declare them with --client-capabilities or --client-preset", client.ErrRootsNotDeclared)
+	}
+	if !caps.Roots.ListChanged {
+		fmt.Fprintln(os.Stderr, "warning: client capabilities do not declare roots.listChanged; the server is not notified of the change")
+	}
+	return c.SetRoots(ctx, roots)
+}
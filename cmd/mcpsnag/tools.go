@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bigbag/mcpsnag/internal/client"
+	"github.com/bigbag/mcpsnag/internal/jsonschema"
+	"github.com/bigbag/mcpsnag/internal/output"
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func processToolResult(ctx context.Context, c *client.Client, printer *output.Printer, params, raw json.RawMessage, opts runOptions) (json.RawMessage, error) {
+	var result protocol.CallToolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		if opts.structured {
+			return nil, fmt.Errorf("failed to parse tools/call result: %w", err)
+		}
+		return raw, nil
+	}
+	isError := result.IsError != nil && *result.IsError
+
+	var validationErr error
+	if !isError && result.StructuredContent != nil && !opts.noValidate {
+		var call protocol.CallToolParams
+		json.Unmarshal(params, &call)
+		validationErr = validateStructured(ctx, c, printer, call.Name, result.StructuredContent, opts.maxPages)
+	}
+
+	if opts.structured {
+		if isError {
+			return raw, errors.New("tool returned an error")
+		}
+		if result.StructuredContent == nil {
+			return nil, errors.New("tool result has no structuredContent")
+		}
+		return result.StructuredContent, validationErr
+	}
+
+	content, err := resolveLinks(ctx, c, printer, result.Content, opts.followLinks)
+	if err != nil || content == nil {
+		return raw, errors.Join(validationErr, err)
+	}
+
+	result.Content = content
+	out, err := json.Marshal(result)
+	if err != nil {
+		return raw, validationErr
+	}
+	return out, validationErr
+}
+
+func validateStructured(ctx context.Context, c *client.Client, printer *output.Printer, tool string, structured json.RawMessage, maxPages int) error {
+	schema, err := c.ToolOutputSchema(ctx, tool, maxPages)
+	if err != nil {
+		printer.PrintVerbose("* Could not look up the outputSchema of %s: %v", tool, err)
+		return nil
+	}
+	if schema == nil {
+		printer.PrintVerbose("* Tool %s declares no outputSchema, structuredContent not validated", tool)
+		return nil
+	}
+
+	err = jsonschema.Validate(schema, structured)
+	var mismatch *jsonschema.ValidationError
+	if errors.As(err, &mismatch) {
+		return fmt.Errorf("structuredContent does not match the outputSchema of %s: %w", tool, err)
+	}
+	if err != nil {
+		printer.PrintVerbose("* Cannot use the outputSchema of %s (%v), structuredContent not validated", tool, err)
+		return nil
+	}
+	printer.PrintVerbose("* structuredContent matches the outputSchema of %s", tool)
+	return nil
+}
+
+func resolveLinks(ctx context.Context, c *client.Client, printer *output.Printer, content []protocol.Content, follow bool) ([]protocol.Content, error) {
+	var resolved []protocol.Content
+	changed := false
+	var errs []error
+
+	for _, block := range content {
+		link, ok := block.ContentBlock.(*protocol.ResourceLink)
+		if !ok || link.URI == "" {
+			resolved = append(resolved, block)
+			continue
+		}
+
+		if !follow {
+			printer.PrintVerbose("* Resource link: %s (%s) - use --follow-links to read it", link.URI, describeLink(link))
+			resolved = append(resolved, block)
+			continue
+		}
+
+		printer.PrintVerbose("* Following resource link %s", link.URI)
+		read, err := c.ReadResource(ctx, link.URI)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read resource link %s: %w", link.URI, err))
+			resolved = append(resolved, block)
+			continue
+		}
+		for _, contents := range read.Contents {
+			resolved = append(resolved, protocol.Content{ContentBlock: &protocol.EmbeddedResource{Resource: contents}})
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil, errors.Join(errs...)
+	}
+	return resolved, errors.Join(errs...)
+}
+
+func describeLink(link *protocol.ResourceLink) string {
+	desc := link.Name
+	if link.Title != "" {
+		desc = link.Title
+	}
+	if desc == "" {
+		desc = "unnamed"
+	}
+	if link.MimeType != "" {
+		desc += ", " + link.MimeType
+	}
+	return desc
+}
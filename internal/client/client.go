@@ -1,19 +1,62 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/bigbag/mcpsnag/internal/protocol"
 )
 
+type Conn interface {
+	PostAndReadResponse(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) (*protocol.Response, string, error)
+	SetHeader(key, value string)
+	SetRequestHandler(handler func(protocol.Message))
+	Close() error
+}
+
+type BatchConn interface {
+	PostBatch(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error)
+}
+
+type RequestHandler func(params json.RawMessage) (any, error)
+
+type Listener interface {
+	Listen(ctx context.Context, onMessage func(json.RawMessage) error) error
+}
+
+type Terminator interface {
+	Terminate() error
+}
+
+const cancelNotifyTimeout = 5 * time.Second
+
+const (
+	HTTPModeAuto       = "auto"
+	HTTPModeStreamable = "streamable"
+	HTTPModeLegacySSE  = "sse"
+)
+
 type Client struct {
-	transport *Transport
+	transport Conn
 	session   *Session
 	requestID atomic.Int64
 	stream    bool
+	opts      Options
+	handlers  map[string]RequestHandler
+	tlsConfig *tls.Config
+	dialer    *Dialer
+
+	rootsMu sync.Mutex
+	roots   []protocol.Root
 }
 
 type Options struct {
@@ -22,32 +65,250 @@ type Options struct {
 	SessionID string
 	Timeout   time.Duration
 	Stream    bool
+	Command   []string
+	Stderr    io.Writer
+
+	UnixSocket      string
+	TLS             TLSOptions
+	Network         NetworkOptions
+	HTTPMode        string
+	MaxReconnects   int
+	MaxEventSize    int
+	ProtocolVersion string
+	Capabilities    *protocol.ClientCapabilities
+	ClientInfo      *protocol.Implementation
+	Roots           []protocol.Root
+	Retry           RetryPolicy
+	Logger          func(format string, args ...any)
+
+	ReinitOnExpiry   bool
+	OnSessionRenewed func(expiredID, newID string)
+	OnLogMessage     func(protocol.LoggingMessageParams)
 }
 
-func New(opts Options) *Client {
-	t := NewTransport(opts.Endpoint, opts.Timeout)
+func New(opts Options) (*Client, error) {
+	if strings.HasPrefix(opts.Endpoint, unixScheme) {
+		socket, endpoint, err := ParseUnixEndpoint(opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts.UnixSocket, opts.Endpoint = socket, endpoint
+	}
+
+	if opts.ProtocolVersion != "" && !protocol.IsSupportedVersion(opts.ProtocolVersion) {
+		return nil, fmt.Errorf("unsupported protocol version %q (supported: %s)", opts.ProtocolVersion, strings.Join(protocol.SupportedVersions, ", "))
+	}
+
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := NewDialer(opts.Network, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	dialer.SetLogger(opts.Logger)
+
+	var t Conn
+	switch {
+	case len(opts.Command) > 0:
+		st, err := NewStdioTransport(opts.Command, opts.Stderr, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		t = st
+	case IsWebSocketURL(opts.Endpoint):
+		wt := NewWebSocketTransport(opts.Endpoint, opts.Timeout)
+		wt.SetMaxMessageSize(opts.MaxEventSize)
+		wt.SetLogger(opts.Logger)
+		wt.SetDialer(dialer)
+		if opts.UnixSocket != "" {
+			wt.SetUnixSocket(opts.UnixSocket)
+		}
+		if tlsConfig != nil {
+			wt.SetTLSConfig(tlsConfig)
+		}
+		t = wt
+	case opts.HTTPMode == HTTPModeLegacySSE:
+		t = newLegacyTransport(opts, tlsConfig, dialer)
+	case opts.HTTPMode == "" || opts.HTTPMode == HTTPModeAuto || opts.HTTPMode == HTTPModeStreamable:
+		ht := NewTransport(opts.Endpoint, opts.Timeout)
+		ht.SetMaxReconnects(opts.MaxReconnects)
+		ht.SetMaxEventSize(opts.MaxEventSize)
+		ht.SetRetryPolicy(opts.Retry)
+		ht.SetLogger(opts.Logger)
+		ht.SetDialer(dialer)
+		if opts.UnixSocket != "" {
+			ht.SetUnixSocket(opts.UnixSocket)
+		}
+		if tlsConfig != nil {
+			ht.SetTLSConfig(tlsConfig)
+		}
+		t = ht
+	default:
+		return nil, fmt.Errorf("unknown HTTP transport mode %q", opts.HTTPMode)
+	}
+
 	for k, v := range opts.Headers {
 		t.SetHeader(k, v)
 	}
 
 	session := &Session{ID: opts.SessionID}
 	if session.ID != "" {
+		session.ProtocolVersion = opts.protocolVersion()
 		t.SetHeader(protocol.SessionHeader, session.ID)
+		t.SetHeader(protocol.ProtocolVersionHeader, session.ProtocolVersion)
 	}
 
-	return &Client{
+	c := &Client{
 		transport: t,
 		session:   session,
 		stream:    opts.Stream,
+		opts:      opts,
+		handlers:  make(map[string]RequestHandler),
+		tlsConfig: tlsConfig,
+		dialer:    dialer,
+		roots:     opts.Roots,
+	}
+	if opts.UnixSocket != "" && len(opts.Command) == 0 {
+		c.logf("* Connecting via Unix socket %s", opts.UnixSocket)
+	}
+	if opts.TLS.Insecure {
+		c.logf("* WARNING: TLS certificate verification is disabled")
 	}
+	c.Handle("ping", func(json.RawMessage) (any, error) {
+		return struct{}{}, nil
+	})
+	c.Handle("roots/list", func(json.RawMessage) (any, error) {
+		return protocol.ListRootsResult{Roots: c.Roots()}, nil
+	})
+	t.SetRequestHandler(c.handleServerRequest)
+
+	return c, nil
+}
+
+func (c *Client) Handle(method string, handler RequestHandler) {
+	c.handlers[method] = handler
+}
+
+func (c *Client) handleServerRequest(msg protocol.Message) {
+	c.logf("* Server request %s (id %v)", msg.Method, msg.ID)
+
+	var resp *protocol.Response
+	handler, ok := c.handlers[msg.Method]
+	if !ok {
+		resp = protocol.NewErrorResponse(msg.ID, protocol.CodeMethodNotFound, "Method not found: "+msg.Method)
+	} else if result, err := handler(msg.Params); err != nil {
+		var rpcErr *protocol.Error
+		if errors.As(err, &rpcErr) {
+			resp = &protocol.Response{JSONRPC: protocol.JSONRPCVersion, ID: msg.ID, Error: rpcErr}
+		} else {
+			resp = protocol.NewErrorResponse(msg.ID, protocol.CodeInternalError, err.Error())
+		}
+	} else if resp, err = protocol.NewResponse(msg.ID, result); err != nil {
+		resp = protocol.NewErrorResponse(msg.ID, protocol.CodeInternalError, err.Error())
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		c.logf("* Failed to encode reply to %s: %v", msg.Method, err)
+		return
+	}
+	if _, _, err := c.transport.PostAndReadResponse(context.Background(), body, false, nil); err != nil {
+		c.logf("* Failed to reply to %s: %v", msg.Method, err)
+	}
+}
+
+func (o Options) clientCapabilities() protocol.ClientCapabilities {
+	if o.Capabilities != nil {
+		return *o.Capabilities
+	}
+	return protocol.DefaultInitializeParams().Capabilities
+}
+
+func (o Options) protocolVersion() string {
+	if o.ProtocolVersion != "" {
+		return o.ProtocolVersion
+	}
+	return protocol.MCPVersion
+}
+
+func newLegacyTransport(opts Options, tlsConfig *tls.Config, dialer *Dialer) *LegacyTransport {
+	lt := NewLegacyTransport(opts.Endpoint, opts.Timeout)
+	lt.SetLogger(opts.Logger)
+	lt.SetMaxEventSize(opts.MaxEventSize)
+	lt.SetDialer(dialer)
+	if opts.UnixSocket != "" {
+		lt.SetUnixSocket(opts.UnixSocket)
+	}
+	if tlsConfig != nil {
+		lt.SetTLSConfig(tlsConfig)
+	}
+	for k, v := range opts.Headers {
+		lt.SetHeader(k, v)
+	}
+	return lt
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.opts.Logger != nil {
+		c.opts.Logger(format, args...)
+	}
+}
+
+func (c *Client) shouldFallBack(err error) bool {
+	if c.opts.HTTPMode != "" && c.opts.HTTPMode != HTTPModeAuto {
+		return false
+	}
+	if _, ok := c.transport.(*Transport); !ok {
+		return false
+	}
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500
+}
+
+func (c *Client) sessionExpired(err error) bool {
+	if !c.opts.ReinitOnExpiry || !c.session.IsValid() {
+		return false
+	}
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) reinitialize(ctx context.Context) error {
+	expiredID := c.session.ID
+	c.logf("* Session %s expired, re-initializing", expiredID)
+
+	c.session.ID = ""
+	c.transport.SetHeader(protocol.SessionHeader, "")
+	c.transport.SetHeader(protocol.ProtocolVersionHeader, "")
+	if _, err := c.Initialize(ctx); err != nil {
+		return fmt.Errorf("session %s expired and re-initialization failed: %w", expiredID, err)
+	}
+
+	c.logf("* Session ID: %s", c.session.ID)
+	if c.opts.OnSessionRenewed != nil {
+		c.opts.OnSessionRenewed(expiredID, c.session.ID)
+	}
+	return nil
+}
+
+func (c *Client) Close() error {
+	return c.transport.Close()
 }
 
 func (c *Client) nextID() int64 {
 	return c.requestID.Add(1)
 }
 
-func (c *Client) Initialize() (*protocol.InitializeResult, error) {
+func (c *Client) Initialize(ctx context.Context) (*protocol.InitializeResult, error) {
 	params := protocol.DefaultInitializeParams()
+	params.ProtocolVersion = c.opts.protocolVersion()
+	params.Capabilities = c.opts.clientCapabilities()
+	if c.opts.ClientInfo != nil {
+		params.ClientInfo = *c.opts.ClientInfo
+	}
+	c.warnUnhandledCapabilities(params.Capabilities)
 	req, err := protocol.NewRequest(c.nextID(), "initialize", params)
 	if err != nil {
 		return nil, err
@@ -58,7 +319,27 @@ func (c *Client) Initialize() (*protocol.InitializeResult, error) {
 		return nil, err
 	}
 
-	resp, sessionID, err := c.transport.PostAndReadResponse(body, false, nil)
+	onEvent, stream := c.logFilter(nil, false)
+	resp, sessionID, err := c.transport.PostAndReadResponse(ctx, body, stream, onEvent)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("initialize cancelled: %w", context.Cause(ctx))
+	}
+	if err != nil && c.shouldFallBack(err) {
+		c.logf("* Streamable HTTP initialize failed (%v), falling back to HTTP+SSE", err)
+		c.transport.Close()
+		c.transport = newLegacyTransport(c.opts, c.tlsConfig, c.dialer)
+		c.transport.SetRequestHandler(c.handleServerRequest)
+
+		var legacyErr error
+		resp, sessionID, legacyErr = c.transport.PostAndReadResponse(ctx, body, stream, onEvent)
+		if legacyErr != nil && ctx.Err() != nil {
+			return nil, fmt.Errorf("initialize cancelled: %w", context.Cause(ctx))
+		}
+		if legacyErr != nil {
+			return nil, fmt.Errorf("%w; HTTP+SSE fallback: %v", err, legacyErr)
+		}
+		err = nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -81,45 +362,121 @@ func (c *Client) Initialize() (*protocol.InitializeResult, error) {
 		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 	}
 
+	if err := c.checkProtocolVersion(params.ProtocolVersion, result.ProtocolVersion); err != nil {
+		return nil, err
+	}
+	c.logf("* Protocol version: %s", result.ProtocolVersion)
+	c.session.ProtocolVersion = result.ProtocolVersion
+	c.transport.SetHeader(protocol.ProtocolVersionHeader, result.ProtocolVersion)
+
 	c.session.Capabilities = &result.Capabilities
 	c.session.ServerInfo = &result.ServerInfo
 
-	notif, err := protocol.NewNotification("notifications/initialized", nil)
-	if err != nil {
-		return nil, err
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("initialize cancelled: %w", context.Cause(ctx))
+		}
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
 	}
 
-	notifBody, err := json.Marshal(notif)
+	return &result, nil
+}
+
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	notif, err := protocol.NewNotification(method, params)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	_, _, err = c.transport.PostAndReadResponse(notifBody, false, nil)
+	body, err := json.Marshal(notif)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+		return err
 	}
 
-	return &result, nil
+	_, _, err = c.transport.PostAndReadResponse(ctx, body, false, nil)
+	return err
+}
+
+func (c *Client) warnUnhandledCapabilities(caps protocol.ClientCapabilities) {
+	if _, ok := c.handlers["sampling/createMessage"]; caps.Sampling != nil && !ok {
+		c.logf("* Declaring sampling, but sampling/createMessage requests will be answered with Method not found")
+	}
+	if _, ok := c.handlers["elicitation/create"]; caps.Elicitation != nil && !ok {
+		c.logf("* Declaring elicitation, but elicitation/create requests will be answered with Method not found")
+	}
+}
+
+func (c *Client) checkProtocolVersion(requested, negotiated string) error {
+	if negotiated == "" {
+		return errors.New("server did not return a protocol version")
+	}
+	if !protocol.IsSupportedVersion(negotiated) {
+		return fmt.Errorf("server negotiated unsupported protocol version %q (supported: %s)", negotiated, strings.Join(protocol.SupportedVersions, ", "))
+	}
+	if c.opts.ProtocolVersion != "" && negotiated != requested {
+		return fmt.Errorf("server negotiated protocol version %q, but --protocol-version %s was requested", negotiated, requested)
+	}
+	return nil
 }
 
 func (c *Client) Session() *Session {
 	return c.session
 }
 
-func (c *Client) Request(method string, params json.RawMessage, onEvent func(protocol.Response) error) (*protocol.Response, error) {
-	req, err := protocol.NewRequest(c.nextID(), method, nil)
+func (c *Client) ClientCapabilities() protocol.ClientCapabilities {
+	return c.opts.clientCapabilities()
+}
+
+type RequestOption func(*requestConfig)
+
+type requestConfig struct {
+	onProgress func(protocol.ProgressParams)
+}
+
+func WithProgress(onProgress func(protocol.ProgressParams)) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.onProgress = onProgress
+	}
+}
+
+func (c *Client) Request(ctx context.Context, method string, params json.RawMessage, onEvent func(protocol.Message) error, opts ...RequestOption) (*protocol.Response, error) {
+	var cfg requestConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	id := c.nextID()
+	req, err := protocol.NewRequest(id, method, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Params = params
 
+	onEvent, stream := c.logFilter(onEvent, c.stream)
+	if cfg.onProgress != nil {
+		if req.Params, err = protocol.WithProgressToken(params, id); err != nil {
+			return nil, err
+		}
+		onEvent = progressFilter(id, cfg.onProgress, onEvent, stream)
+		stream = true
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, _, err := c.transport.PostAndReadResponse(body, c.stream, onEvent)
+	resp, _, err := c.transport.PostAndReadResponse(ctx, body, stream, onEvent)
+	if err != nil && c.sessionExpired(err) {
+		if err := c.reinitialize(ctx); err != nil {
+			return nil, err
+		}
+		resp, _, err = c.transport.PostAndReadResponse(ctx, body, stream, onEvent)
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, c.cancelRequest(id, context.Cause(ctx))
+		}
 		return nil, err
 	}
 
@@ -130,6 +487,151 @@ func (c *Client) Request(method string, params json.RawMessage, onEvent func(pro
 	return resp, nil
 }
 
-func (c *Client) RawRequest(body []byte, onEvent func(protocol.Response) error) (*protocol.Response, string, error) {
-	return c.transport.PostAndReadResponse(body, c.stream, onEvent)
+func progressFilter(token any, onProgress func(protocol.ProgressParams), onEvent func(protocol.Message) error, stream bool) func(protocol.Message) error {
+	return func(msg protocol.Message) error {
+		if msg.Method == "notifications/progress" {
+			var p protocol.ProgressParams
+			if err := json.Unmarshal(msg.Params, &p); err == nil && protocol.SameID(p.ProgressToken, token) {
+				onProgress(p)
+				return nil
+			}
+		}
+		if stream && onEvent != nil {
+			return onEvent(msg)
+		}
+		return nil
+	}
+}
+
+func (c *Client) Listen(ctx context.Context, onMessage func(json.RawMessage) error) error {
+	l, ok := c.transport.(Listener)
+	if !ok {
+		return errors.New("transport does not support listening for server messages")
+	}
+	if c.opts.OnLogMessage == nil {
+		return l.Listen(ctx, onMessage)
+	}
+	return l.Listen(ctx, func(raw json.RawMessage) error {
+		var msg protocol.Message
+		if json.Unmarshal(raw, &msg) == nil && c.handleLogMessage(msg) {
+			return nil
+		}
+		return onMessage(raw)
+	})
+}
+
+func (c *Client) Terminate() error {
+	if !c.session.IsValid() {
+		return errors.New("no session to terminate")
+	}
+
+	t, ok := c.transport.(Terminator)
+	if !ok {
+		return errors.New("transport does not support session termination")
+	}
+
+	if err := t.Terminate(); err != nil {
+		return err
+	}
+
+	c.session.ID = ""
+	c.transport.SetHeader(protocol.SessionHeader, "")
+	return nil
+}
+
+func (c *Client) RawRequest(ctx context.Context, body []byte, onEvent func(protocol.Message) error) (*protocol.Response, string, error) {
+	onEvent, stream := c.logFilter(onEvent, c.stream)
+	resp, sessionID, err := c.transport.PostAndReadResponse(ctx, body, stream, onEvent)
+	if err != nil && ctx.Err() != nil {
+		if id, expectsResponse := parseEnvelope(body); expectsResponse && id != nil {
+			return nil, sessionID, c.cancelRequest(id, context.Cause(ctx))
+		}
+		return nil, sessionID, fmt.Errorf("request cancelled: %w", context.Cause(ctx))
+	}
+	return resp, sessionID, err
+}
+
+type BatchResult struct {
+	ID       any
+	Method   string
+	Response *protocol.Response
+}
+
+func (c *Client) Batch(ctx context.Context, calls []protocol.UserRequest, onEvent func(protocol.Message) error) ([]BatchResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("batch must contain at least one request")
+	}
+
+	reqs := make([]protocol.Request, len(calls))
+	var results []BatchResult
+	for i, call := range calls {
+		if call.Method == "" {
+			return nil, fmt.Errorf("missing 'method' field in batch item %d", i)
+		}
+		reqs[i] = protocol.Request{JSONRPC: protocol.JSONRPCVersion, Method: call.Method, Params: call.Params}
+		if !strings.HasPrefix(call.Method, "notifications/") {
+			reqs[i].ID = c.nextID()
+			results = append(results, BatchResult{ID: reqs[i].ID, Method: call.Method})
+		}
+	}
+
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, _, err := c.RawBatch(ctx, body, onEvent)
+	if err != nil && c.sessionExpired(err) {
+		if err := c.reinitialize(ctx); err != nil {
+			return nil, err
+		}
+		responses, _, err = c.RawBatch(ctx, body, onEvent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Response = responses[i]
+	}
+	return results, nil
+}
+
+func (c *Client) RawBatch(ctx context.Context, body []byte, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error) {
+	bc, ok := c.transport.(BatchConn)
+	if !ok {
+		return nil, "", errors.New("transport does not support batch requests")
+	}
+
+	onEvent, stream := c.logFilter(onEvent, c.stream)
+	responses, sessionID, err := bc.PostBatch(ctx, body, stream, onEvent)
+	if err != nil && ctx.Err() != nil {
+		ids, _ := parseBatchIDs(body)
+		for _, id := range ids {
+			c.cancelRequest(id, context.Cause(ctx))
+		}
+		return nil, sessionID, fmt.Errorf("request cancelled: %w", context.Cause(ctx))
+	}
+	return responses, sessionID, err
+}
+
+func (c *Client) cancelRequest(id any, cause error) error {
+	c.logf("* Cancelling request %v: %v", id, cause)
+
+	notif, err := protocol.NewNotification("notifications/cancelled", protocol.CancelledParams{
+		RequestID: id,
+		Reason:    cause.Error(),
+	})
+	if err == nil {
+		var body []byte
+		if body, err = json.Marshal(notif); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+			_, _, err = c.transport.PostAndReadResponse(ctx, body, false, nil)
+			cancel()
+		}
+	}
+	if err != nil {
+		c.logf("* Failed to send notifications/cancelled: %v", err)
+	}
+
+	return fmt.Errorf("request cancelled: %w", cause)
 }
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+type mcpHandler func(w http.ResponseWriter, r *http.Request, msg protocol.Message)
+
+const (
+	anyMethod   = "*"
+	clientReply = ""
+)
+
+func newMCPServer(t *testing.T, handlers map[string]mcpHandler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg protocol.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		if handler, ok := handlers[msg.Method]; ok {
+			handler(w, r, msg)
+			return
+		}
+		if msg.Method == "initialize" {
+			writeResult(w, msg.ID, initializeResult("2025-06-18", "{}"))
+			return
+		}
+		if handler, ok := handlers[anyMethod]; ok {
+			handler(w, r, msg)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func initializeResult(version, capabilities string) string {
+	return fmt.Sprintf(`{"protocolVersion":%q,"capabilities":%s,"serverInfo":{"name":"test","version":"1"}}`, version, capabilities)
+}
+
+func resultMessage(id any, result string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, mustJSON(id), result)
+}
+
+func writeResult(w http.ResponseWriter, id any, result string) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, resultMessage(id, result))
+}
+
+func writeEvents(w http.ResponseWriter, messages ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, msg := range messages {
+		fmt.Fprintf(w, "data: %s\n\n", msg)
+	}
+}
+
+func newInitializedClient(t *testing.T, opts Options) *Client {
+	t.Helper()
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Second
+	}
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return c
+}
+
+func newExpiringSessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			w.Header().Set(protocol.SessionHeader, "sess-new")
+			writeResult(w, msg.ID, initializeResult("2025-03-26", "{}"))
+		},
+		anyMethod: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			switch {
+			case r.Header.Get(protocol.SessionHeader) != "sess-new":
+				http.Error(w, "session not found", http.StatusNotFound)
+			case msg.ID != nil:
+				writeResult(w, msg.ID, `{"tools":[]}`)
+			default:
+				w.WriteHeader(http.StatusAccepted)
+			}
+		},
+	})
+}
+
+func TestClientReinitOnExpiry(t *testing.T) {
+	server := newExpiringSessionServer(t)
+
+	var renewed []string
+	c, err := New(Options{
+		Endpoint:       server.URL,
+		SessionID:      "sess-old",
+		Timeout:        time.Second,
+		ReinitOnExpiry: true,
+		OnSessionRenewed: func(expiredID, newID string) {
+			renewed = append(renewed, expiredID+" -> "+newID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	resp, err := c.Request(context.Background(), "tools/list", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if string(resp.Result) != `{"tools":[]}` {
+		t.Errorf("expected replayed result, got %s", resp.Result)
+	}
+	if c.Session().ID != "sess-new" {
+		t.Errorf("expected new session ID, got %q", c.Session().ID)
+	}
+	if len(renewed) != 1 || renewed[0] != "sess-old -> sess-new" {
+		t.Errorf("expected one renewal, got %v", renewed)
+	}
+}
+
+func TestClientNoReinitByDefault(t *testing.T) {
+	server := newExpiringSessionServer(t)
+
+	c, err := New(Options{Endpoint: server.URL, SessionID: "sess-old", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, err = c.Request(context.Background(), "tools/list", nil, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected HTTP 404 without --reinit-on-expiry, got %v", err)
+	}
+	if c.Session().ID != "sess-old" {
+		t.Errorf("expected session to be kept, got %q", c.Session().ID)
+	}
+}
+
+func newVersionServer(t *testing.T, version string, headers *[]string) *httptest.Server {
+	t.Helper()
+	record := func(r *http.Request, msg protocol.Message) {
+		*headers = append(*headers, msg.Method+"="+r.Header.Get(protocol.ProtocolVersionHeader))
+	}
+	return newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			record(r, msg)
+			writeResult(w, msg.ID, initializeResult(version, "{}"))
+		},
+		anyMethod: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			record(r, msg)
+			if msg.ID == nil {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			writeResult(w, msg.ID, "{}")
+		},
+	})
+}
+
+func TestClientProtocolVersionHeader(t *testing.T) {
+	var headers []string
+	server := newVersionServer(t, "2025-06-18", &headers)
+
+	c := newInitializedClient(t, Options{Endpoint: server.URL, ProtocolVersion: "2025-06-18"})
+	if _, err := c.Request(context.Background(), "ping", nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	expected := []string{"initialize=", "notifications/initialized=2025-06-18", "ping=2025-06-18"}
+	if strings.Join(headers, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected headers %v, got %v", expected, headers)
+	}
+	if c.Session().ProtocolVersion != "2025-06-18" {
+		t.Errorf("expected negotiated version on session, got %q", c.Session().ProtocolVersion)
+	}
+}
+
+func TestClientProtocolVersionMismatch(t *testing.T) {
+	tests := []struct {
+		requested  string
+		negotiated string
+		wantErr    string
+	}{
+		{"", "2024-11-05", ""},
+		{"", "1999-01-01", "unsupported protocol version"},
+		{"2025-06-18", "2025-03-26", "was requested"},
+		{"", "", "did not return a protocol version"},
+	}
+	for _, tt := range tests {
+		var headers []string
+		server := newVersionServer(t, tt.negotiated, &headers)
+
+		c, err := New(Options{Endpoint: server.URL, Timeout: time.Second, ProtocolVersion: tt.requested})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		_, err = c.Initialize(context.Background())
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%q/%q: unexpected error %v", tt.requested, tt.negotiated, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%q/%q: expected error containing %q, got %v", tt.requested, tt.negotiated, tt.wantErr, err)
+		}
+	}
+
+	if _, err := New(Options{Endpoint: "http://localhost/mcp", ProtocolVersion: "2020-01-01"}); err == nil {
+		t.Error("expected error for unsupported --protocol-version")
+	}
+}
+
+func TestClientInitializeCancelCause(t *testing.T) {
+	server := newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			<-r.Context().Done()
+		},
+	})
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	cause := errors.New("cancel-after deadline reached")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, cause)
+	defer cancel()
+
+	_, err = c.Initialize(ctx)
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected cancellation cause in error, got %v", err)
+	}
+}
+
+func TestClientInitializeClientProfile(t *testing.T) {
+	var sent protocol.InitializeParams
+	server := newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			json.Unmarshal(msg.Params, &sent)
+			writeResult(w, msg.ID, initializeResult("2025-06-18", "{}"))
+		},
+	})
+
+	newInitializedClient(t, Options{
+		Endpoint: server.URL,
+		Capabilities: &protocol.ClientCapabilities{
+			Elicitation:  &protocol.ElicitationCapability{},
+			Experimental: map[string]json.RawMessage{"tasks": json.RawMessage(`{}`)},
+		},
+		ClientInfo: &protocol.Implementation{Name: "custom-host", Title: "Custom Host", Version: "9.9.9"},
+	})
+
+	if sent.ClientInfo != (protocol.Implementation{Name: "custom-host", Title: "Custom Host", Version: "9.9.9"}) {
+		t.Errorf("unexpected clientInfo %+v", sent.ClientInfo)
+	}
+	if sent.Capabilities.Roots != nil || sent.Capabilities.Elicitation == nil {
+		t.Errorf("expected only the configured capabilities, got %+v", sent.Capabilities)
+	}
+	if _, ok := sent.Capabilities.Experimental["tasks"]; !ok {
+		t.Errorf("expected experimental tasks capability, got %+v", sent.Capabilities.Experimental)
+	}
+}
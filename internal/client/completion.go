@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+var ErrCompletionsNotSupported = errors.New("server did not advertise the completions capability")
+
+func (c *Client) Complete(ctx context.Context, params protocol.CompleteParams) (*protocol.Completion, error) {
+	if caps := c.session.Capabilities; caps == nil {
+		c.logf("* Server capabilities unknown, skipping the completions capability check")
+	} else if caps.Completions == nil {
+		return nil, ErrCompletionsNotSupported
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Request(ctx, "completion/complete", raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response to completion/complete")
+	}
+
+	var result protocol.CompleteResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse completion/complete result: %w", err)
+	}
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	return &result.Completion, nil
+}
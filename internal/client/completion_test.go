@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newCompletionServer(t *testing.T, capabilities string, params *protocol.CompleteParams) *Client {
+	t.Helper()
+	server := newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			writeResult(w, msg.ID, initializeResult("2025-06-18", capabilities))
+		},
+		"completion/complete": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			json.Unmarshal(msg.Params, params)
+			writeResult(w, msg.ID, `{"completion":{"values":["python","pytorch"],"total":5,"hasMore":true}}`)
+		},
+	})
+	return newInitializedClient(t, Options{Endpoint: server.URL})
+}
+
+func TestClientComplete(t *testing.T) {
+	var sent protocol.CompleteParams
+	c := newCompletionServer(t, `{"completions":{},"prompts":{}}`, &sent)
+
+	completion, err := c.Complete(context.Background(), protocol.CompleteParams{
+		Ref:      protocol.ParseCompleteReference("file:///{path}"),
+		Argument: protocol.CompleteArgument{Name: "path", Value: "py"},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(completion.Values) != 2 || completion.Total == nil || *completion.Total != 5 || !completion.HasMore {
+		t.Errorf("unexpected completion %+v", completion)
+	}
+	if sent.Ref.Type != protocol.RefTypeResource || sent.Ref.URI != "file:///{path}" || sent.Argument.Value != "py" {
+		t.Errorf("unexpected params sent %+v", sent)
+	}
+}
+
+func TestClientCompleteNotAdvertised(t *testing.T) {
+	var sent protocol.CompleteParams
+	c := newCompletionServer(t, `{"prompts":{}}`, &sent)
+
+	_, err := c.Complete(context.Background(), protocol.CompleteParams{
+		Ref:      protocol.ParseCompleteReference("code_review"),
+		Argument: protocol.CompleteArgument{Name: "language"},
+	})
+	if !errors.Is(err, ErrCompletionsNotSupported) {
+		t.Errorf("expected ErrCompletionsNotSupported, got %v", err)
+	}
+	if sent.Argument.Name != "" {
+		t.Error("expected no completion/complete request to be sent")
+	}
+}
+
+func TestClientCompleteNoResponse(t *testing.T) {
+	server := newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			writeResult(w, msg.ID, initializeResult("2025-06-18", `{"completions":{}}`))
+		},
+		"completion/complete": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			w.WriteHeader(http.StatusAccepted)
+		},
+	})
+	c := newInitializedClient(t, Options{Endpoint: server.URL})
+
+	_, err := c.Complete(context.Background(), protocol.CompleteParams{
+		Ref:      protocol.ParseCompleteReference("code_review"),
+		Argument: protocol.CompleteArgument{Name: "language"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no response to completion/complete") {
+		t.Errorf("expected missing response error, got %v", err)
+	}
+}
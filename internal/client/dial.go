@@ -0,0 +1,363 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NetworkOptions struct {
+	Proxy     string
+	NoProxy   string
+	Resolve   []string
+	ConnectTo []string
+}
+
+type hostRule struct {
+	host   string
+	port   string
+	toHost string
+	toPort string
+}
+
+type Dialer struct {
+	net       net.Dialer
+	proxyURL  *url.URL
+	noProxy   []string
+	resolve   []hostRule
+	connectTo []hostRule
+	logger    func(format string, args ...any)
+}
+
+func NewDialer(opts NetworkOptions, timeout time.Duration) (*Dialer, error) {
+	d := &Dialer{net: net.Dialer{Timeout: timeout}}
+
+	if opts.Proxy != "" {
+		proxy := opts.Proxy
+		if !strings.Contains(proxy, "://") {
+			proxy = "http://" + proxy
+		}
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", opts.Proxy, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https, socks5 or socks5h)", u.Scheme)
+		}
+		d.proxyURL = u
+	}
+
+	for _, entry := range strings.Split(opts.NoProxy, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			d.noProxy = append(d.noProxy, strings.ToLower(entry))
+		}
+	}
+
+	for _, r := range opts.Resolve {
+		fields := splitHostFields(r)
+		if len(fields) != 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+			return nil, fmt.Errorf("invalid --resolve %q (expected host:port:addr)", r)
+		}
+		addr, _, _ := strings.Cut(fields[2], ",")
+		d.resolve = append(d.resolve, hostRule{host: fields[0], port: fields[1], toHost: addr})
+	}
+
+	for _, r := range opts.ConnectTo {
+		fields := splitHostFields(r)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid --connect-to %q (expected host:port:connect-host:connect-port)", r)
+		}
+		d.connectTo = append(d.connectTo, hostRule{host: fields[0], port: fields[1], toHost: fields[2], toPort: fields[3]})
+	}
+
+	return d, nil
+}
+
+func splitHostFields(s string) []string {
+	var fields []string
+	start, depth := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				fields = append(fields, strings.Trim(s[start:i], "[]"))
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, strings.Trim(s[start:], "[]"))
+}
+
+func (d *Dialer) SetLogger(logger func(format string, args ...any)) {
+	d.logger = logger
+}
+
+func (d *Dialer) logf(format string, args ...any) {
+	if d.logger != nil {
+		d.logger(format, args...)
+	}
+}
+
+func (d *Dialer) target(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	for _, r := range d.connectTo {
+		if (r.host == "" || strings.EqualFold(r.host, host)) && (r.port == "" || r.port == port) {
+			if r.toHost != "" {
+				host = r.toHost
+			}
+			if r.toPort != "" {
+				port = r.toPort
+			}
+			break
+		}
+	}
+	for _, r := range d.resolve {
+		if (r.host == "*" || strings.EqualFold(r.host, host)) && r.port == port {
+			host = r.toHost
+			break
+		}
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	target := d.target(addr)
+	if target != addr {
+		d.logf("* Connecting to %s for %s", target, addr)
+	}
+	return d.net.DialContext(ctx, network, target)
+}
+
+func (d *Dialer) Proxy(req *http.Request) (*url.URL, error) {
+	if d.bypassProxy(req.URL.Hostname()) {
+		return nil, nil
+	}
+	if d.proxyURL != nil {
+		return d.proxyURL, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+func (d *Dialer) bypassProxy(host string) bool {
+	host = strings.ToLower(host)
+	addr, addrErr := netip.ParseAddr(host)
+	for _, entry := range d.noProxy {
+		if entry == "*" {
+			return true
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			if addrErr == nil && prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dialer) configure(tr *http.Transport) {
+	tr.Proxy = d.Proxy
+	tr.DialContext = d.DialContext
+}
+
+func (d *Dialer) dialVia(ctx context.Context, proxyURL *url.URL, target string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}[proxyURL.Scheme]
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	d.logf("* Connecting to %s through proxy %s", target, proxyAddr)
+
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if proxyURL.Scheme == "socks5" || proxyURL.Scheme == "socks5h" {
+		err = socks5Connect(conn, target, proxyURL.User)
+	} else {
+		err = httpConnect(conn, target, proxyURL.User)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func httpConnect(conn net.Conn, target string, user *url.Userinfo) error {
+	req := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	if user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("proxy CONNECT failed: %s", resp.Status)
+	}
+	return nil
+}
+
+const (
+	socksVersion      = 5
+	socksAuthNone     = 0
+	socksAuthPassword = 2
+	socksNoAcceptable = 0xFF
+	socksCmdConnect   = 1
+	socksAtypIPv4     = 1
+	socksAtypDomain   = 3
+	socksAtypIPv6     = 4
+)
+
+func socks5Connect(conn net.Conn, target string, user *url.Userinfo) error {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port in %q", target)
+	}
+
+	methods := []byte{socksAuthNone}
+	if user != nil {
+		methods = append(methods, socksAuthPassword)
+	}
+	if _, err := conn.Write(append([]byte{socksVersion, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+
+	var reply [2]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return fmt.Errorf("SOCKS5 handshake failed: %w", err)
+	}
+	if reply[0] != socksVersion {
+		return fmt.Errorf("SOCKS5 handshake failed: unexpected version %d", reply[0])
+	}
+	switch reply[1] {
+	case socksAuthNone:
+	case socksAuthPassword:
+		if user == nil {
+			return errors.New("SOCKS5 proxy requires authentication")
+		}
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return errors.New("SOCKS5 credentials too long")
+		}
+		auth := []byte{1, byte(len(user.Username()))}
+		auth = append(auth, user.Username()...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply[:]); err != nil {
+			return fmt.Errorf("SOCKS5 authentication failed: %w", err)
+		}
+		if reply[1] != 0 {
+			return errors.New("SOCKS5 authentication rejected")
+		}
+	case socksNoAcceptable:
+		return errors.New("SOCKS5 proxy accepted none of the offered authentication methods")
+	default:
+		return fmt.Errorf("SOCKS5 proxy selected unsupported authentication method %d", reply[1])
+	}
+
+	req := []byte{socksVersion, socksCmdConnect, 0}
+	if addr, err := netip.ParseAddr(host); err == nil && addr.Is4() {
+		req = append(req, socksAtypIPv4)
+		req = append(req, addr.AsSlice()...)
+	} else if err == nil {
+		req = append(req, socksAtypIPv6)
+		req = append(req, addr.AsSlice()...)
+	} else {
+		if len(host) > 255 {
+			return errors.New("SOCKS5 target host name too long")
+		}
+		req = append(req, socksAtypDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return fmt.Errorf("SOCKS5 connect failed: %w", err)
+	}
+	if header[1] != 0 {
+		return fmt.Errorf("SOCKS5 connect to %s failed with code %d", target, header[1])
+	}
+
+	var skip int
+	switch header[3] {
+	case socksAtypIPv4:
+		skip = 4
+	case socksAtypIPv6:
+		skip = 16
+	case socksAtypDomain:
+		var n [1]byte
+		if _, err := io.ReadFull(conn, n[:]); err != nil {
+			return err
+		}
+		skip = int(n[0])
+	default:
+		return fmt.Errorf("SOCKS5 reply has unknown address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
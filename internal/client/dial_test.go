@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDialerErrors(t *testing.T) {
+	tests := []NetworkOptions{
+		{Proxy: "ftp://proxy:21"},
+		{Resolve: []string{"example.com:443"}},
+		{Resolve: []string{"example.com::127.0.0.1"}},
+		{ConnectTo: []string{"example.com:443:backend"}},
+	}
+	for _, opts := range tests {
+		if _, err := NewDialer(opts, time.Second); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestDialerTarget(t *testing.T) {
+	d, err := NewDialer(NetworkOptions{
+		Resolve:   []string{"api.example.com:443:10.0.0.1", "v6.example.com:443:[::1]", "*:8080:10.0.0.9"},
+		ConnectTo: []string{"mcp.example.com:443:backend.internal:8443", "legacy.example.com:80:api.example.com:443", ":80:alt.internal:"},
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("NewDialer failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"api.example.com:443":     "10.0.0.1:443",
+		"API.example.com:443":     "10.0.0.1:443",
+		"api.example.com:80":      "alt.internal:80",
+		"v6.example.com:443":      "[::1]:443",
+		"anything.com:8080":       "10.0.0.9:8080",
+		"mcp.example.com:443":     "backend.internal:8443",
+		"legacy.example.com:80":   "10.0.0.1:443",
+		"unmatched.example.com:1": "unmatched.example.com:1",
+	}
+	for addr, want := range tests {
+		if got := d.target(addr); got != want {
+			t.Errorf("target(%q) = %q, expected %q", addr, got, want)
+		}
+	}
+}
+
+func TestDialerBypassProxy(t *testing.T) {
+	d, err := NewDialer(NetworkOptions{Proxy: "proxy:3128", NoProxy: "localhost, .internal,10.0.0.0/8"}, time.Second)
+	if err != nil {
+		t.Fatalf("NewDialer failed: %v", err)
+	}
+	if d.proxyURL.String() != "http://proxy:3128" {
+		t.Errorf("expected default http scheme, got %s", d.proxyURL)
+	}
+
+	for host, want := range map[string]bool{
+		"localhost":       true,
+		"mcp.internal":    true,
+		"internal":        true,
+		"10.1.2.3":        true,
+		"11.1.2.3":        false,
+		"example.com":     false,
+		"notinternal.com": false,
+	} {
+		if got := d.bypassProxy(host); got != want {
+			t.Errorf("bypassProxy(%q) = %v, expected %v", host, got, want)
+		}
+	}
+
+	all, _ := NewDialer(NetworkOptions{NoProxy: "*"}, time.Second)
+	if !all.bypassProxy("example.com") {
+		t.Error("expected * to bypass every host")
+	}
+}
+
+func newEchoHostServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"host":%q}}`, r.Host)
+	}))
+	t.Cleanup(server.Close)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	return server, port
+}
+
+func postPing(t *testing.T, endpoint string, opts NetworkOptions) (string, error) {
+	t.Helper()
+	d, err := NewDialer(opts, time.Second)
+	if err != nil {
+		t.Fatalf("NewDialer failed: %v", err)
+	}
+	tr := NewTransport(endpoint, 5*time.Second)
+	tr.SetDialer(d)
+	defer tr.Close()
+
+	resp, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(resp.Result), nil
+}
+
+func TestTransportResolveAndConnectTo(t *testing.T) {
+	_, port := newEchoHostServer(t)
+
+	result, err := postPing(t, "http://mcp.test:"+port+"/mcp", NetworkOptions{
+		Resolve: []string{"mcp.test:" + port + ":127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("request with --resolve failed: %v", err)
+	}
+	if !strings.Contains(result, `"host":"mcp.test:`+port+`"`) {
+		t.Errorf("expected original Host header, got %s", result)
+	}
+
+	result, err = postPing(t, "http://ingress.test/mcp", NetworkOptions{
+		ConnectTo: []string{"ingress.test:80:127.0.0.1:" + port},
+	})
+	if err != nil {
+		t.Fatalf("request with --connect-to failed: %v", err)
+	}
+	if !strings.Contains(result, `"host":"ingress.test"`) {
+		t.Errorf("expected original Host header, got %s", result)
+	}
+}
+
+func TestTransportHTTPProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String()+" "+r.Header.Get("Proxy-Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"via":"proxy"}}`)
+	}))
+	defer proxy.Close()
+
+	proxyURL := strings.Replace(proxy.URL, "http://", "http://user:pass@", 1)
+	result, err := postPing(t, "http://mcp.test/mcp", NetworkOptions{Proxy: proxyURL})
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	if !strings.Contains(result, "proxy") {
+		t.Errorf("expected proxied response, got %s", result)
+	}
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	if len(proxied) != 1 || proxied[0] != "http://mcp.test/mcp "+auth {
+		t.Errorf("unexpected proxied requests: %v", proxied)
+	}
+
+	_, port := newEchoHostServer(t)
+	result, err = postPing(t, "http://direct.test:"+port+"/mcp", NetworkOptions{
+		Proxy:   proxyURL,
+		NoProxy: "direct.test",
+		Resolve: []string{"direct.test:" + port + ":127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("request bypassing proxy failed: %v", err)
+	}
+	if strings.Contains(result, "proxy") || len(proxied) != 1 {
+		t.Errorf("expected --noproxy host to bypass the proxy, got %s", result)
+	}
+}
+
+func startSOCKS5Server(t *testing.T) (string, *[]string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var targets []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+
+				greeting := make([]byte, 2)
+				io.ReadFull(r, greeting)
+				io.ReadFull(r, make([]byte, greeting[1]))
+				conn.Write([]byte{socksVersion, socksAuthNone})
+
+				header := make([]byte, 4)
+				io.ReadFull(r, header)
+				var host string
+				switch header[3] {
+				case socksAtypIPv4:
+					ip := make([]byte, 4)
+					io.ReadFull(r, ip)
+					host = net.IP(ip).String()
+				case socksAtypDomain:
+					n, _ := r.ReadByte()
+					name := make([]byte, n)
+					io.ReadFull(r, name)
+					host = string(name)
+				}
+				portBytes := make([]byte, 2)
+				io.ReadFull(r, portBytes)
+				target := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+				targets = append(targets, target)
+
+				if host == "ws.test" {
+					host = "127.0.0.1"
+				}
+				upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes)))))
+				if err != nil {
+					conn.Write([]byte{socksVersion, 5, 0, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte{socksVersion, 0, 0, socksAtypIPv4, 127, 0, 0, 1, 0, 0})
+
+				go io.Copy(upstream, r)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), &targets
+}
+
+func TestWebSocketThroughSOCKS5Proxy(t *testing.T) {
+	server := newWebSocketServer(t, func(rw *bufio.ReadWriter) {
+		msg := readClientMessage(t, rw)
+		writeServerMessage(rw, `{"jsonrpc":"2.0","id":`+string(mustJSON(msg.ID))+`,"result":{}}`)
+		readWSFrame(rw, 0)
+	})
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	proxyAddr, targets := startSOCKS5Server(t)
+
+	d, err := NewDialer(NetworkOptions{Proxy: "socks5h://" + proxyAddr}, time.Second)
+	if err != nil {
+		t.Fatalf("NewDialer failed: %v", err)
+	}
+	tr := NewWebSocketTransport("ws://ws.test:"+port+"/mcp", 5*time.Second)
+	tr.SetDialer(d)
+	tr.SetHeader("Authorization", "Bearer secret")
+	defer tr.Close()
+
+	resp, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+	if err != nil {
+		t.Fatalf("PostAndReadResponse through SOCKS5 failed: %v", err)
+	}
+	if resp == nil || string(resp.Result) != "{}" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if len(*targets) != 1 || (*targets)[0] != "ws.test:"+port {
+		t.Errorf("expected proxy to resolve ws.test remotely, got %v", *targets)
+	}
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+type LegacyTransport struct {
+	endpoint     string
+	httpClient   *http.Client
+	headers      map[string]string
+	timeout      time.Duration
+	logger       func(format string, args ...any)
+	maxEventSize int
+
+	postURL   string
+	messages  chan []byte
+	streamErr error
+	cancel    context.CancelFunc
+	onRequest func(protocol.Message)
+}
+
+func NewLegacyTransport(endpoint string, timeout time.Duration) *LegacyTransport {
+	return &LegacyTransport{
+		endpoint:   endpoint,
+		httpClient: newHTTPClient(timeout),
+		headers:    make(map[string]string),
+		timeout:    timeout,
+	}
+}
+
+func (t *LegacyTransport) SetHeader(key, value string) {
+	if value == "" {
+		delete(t.headers, key)
+		return
+	}
+	t.headers[key] = value
+}
+
+func (t *LegacyTransport) SetRequestHandler(handler func(protocol.Message)) {
+	t.onRequest = handler
+}
+
+func (t *LegacyTransport) SetUnixSocket(socket string) {
+	dialUnix(httpTransport(t.httpClient), socket)
+}
+
+func (t *LegacyTransport) SetDialer(d *Dialer) {
+	d.configure(httpTransport(t.httpClient))
+}
+
+func (t *LegacyTransport) SetTLSConfig(cfg *tls.Config) {
+	httpTransport(t.httpClient).TLSClientConfig = cfg
+}
+
+func (t *LegacyTransport) SetMaxEventSize(n int) {
+	t.maxEventSize = n
+}
+
+func (t *LegacyTransport) SetLogger(logger func(format string, args ...any)) {
+	t.logger = logger
+}
+
+func (t *LegacyTransport) logf(format string, args ...any) {
+	if t.logger != nil {
+		t.logger(format, args...)
+	}
+}
+
+func (t *LegacyTransport) connect() error {
+	if t.postURL != "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", t.endpoint, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	streamClient := *t.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	logTLSState(t.logf, resp.TLS)
+
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		return readHTTPError(resp)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		cancel()
+		resp.Body.Close()
+		return fmt.Errorf("unexpected Content-Type %q for SSE stream", contentType)
+	}
+
+	endpoints := make(chan string, 1)
+	streamDone := make(chan struct{})
+	t.messages = make(chan []byte, 16)
+	t.cancel = cancel
+
+	go func() {
+		defer close(streamDone)
+		defer close(t.messages)
+		defer resp.Body.Close()
+
+		err := NewSSEParser(t.maxEventSize).Parse(resp.Body, func(event SSEEvent) error {
+			switch event.Event {
+			case "endpoint":
+				select {
+				case endpoints <- event.Data:
+				default:
+				}
+			case "message", "":
+				if event.Data == "" {
+					return nil
+				}
+				select {
+				case t.messages <- []byte(event.Data):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		if ctx.Err() == nil {
+			t.streamErr = err
+		}
+	}()
+
+	var deadline <-chan time.Time
+	if t.timeout > 0 {
+		timer := time.NewTimer(t.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	select {
+	case endpoint := <-endpoints:
+		postURL, err := resolveEndpoint(t.endpoint, endpoint)
+		if err != nil {
+			cancel()
+			return err
+		}
+		t.postURL = postURL
+		t.logf("* Legacy SSE endpoint: %s", postURL)
+		return nil
+	case <-streamDone:
+		cancel()
+		return errors.New("SSE stream closed before endpoint event")
+	case <-deadline:
+		cancel()
+		return fmt.Errorf("no endpoint event after %s", t.timeout)
+	}
+}
+
+func resolveEndpoint(base, endpoint string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint event %q: %w", endpoint, err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+func (t *LegacyTransport) closedError() error {
+	if t.streamErr != nil {
+		return fmt.Errorf("SSE stream failed: %w", t.streamErr)
+	}
+	return errors.New("SSE stream closed by server")
+}
+
+func (t *LegacyTransport) PostAndReadResponse(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) (*protocol.Response, string, error) {
+	m := newSingleResponse(body)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	return m.response, sessionID, err
+}
+
+func (t *LegacyTransport) PostBatch(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error) {
+	ids, err := parseBatchIDs(body)
+	if err != nil {
+		return nil, "", err
+	}
+	m := newBatchResponses(ids)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	if err != nil {
+		return nil, sessionID, err
+	}
+	responses, err := m.result()
+	return responses, sessionID, err
+}
+
+func (t *LegacyTransport) exchange(ctx context.Context, body []byte, m responseMatcher, stream bool, onEvent func(protocol.Message) error) (string, error) {
+	if err := t.connect(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.postURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", readHTTPError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+
+	return "", awaitResponses(ctx, t.messages, t.closedError, m, t.timeout, stream, onEvent, t.onRequest)
+}
+
+func (t *LegacyTransport) Listen(ctx context.Context, onMessage func(json.RawMessage) error) error {
+	if err := t.connect(); err != nil {
+		return err
+	}
+	return listenMessages(ctx, t.messages, t.closedError, onMessage, t.onRequest)
+}
+
+func (t *LegacyTransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.httpClient.CloseIdleConnections()
+	return nil
+}
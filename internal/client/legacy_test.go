@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newLegacyServer(t *testing.T, ssePath string) *httptest.Server {
+	t.Helper()
+	outbox := make(chan string, 16)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ssePath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?sessionId=abc\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case msg := <-outbox:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sessionId") != "abc" {
+			t.Errorf("expected sessionId query, got %q", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("expected custom header on POST, got %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req protocol.Request
+		json.Unmarshal(body, &req)
+		w.WriteHeader(http.StatusAccepted)
+
+		switch req.Method {
+		case "initialize":
+			outbox <- fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{"protocolVersion":"2024-11-05","capabilities":{},"serverInfo":{"name":"legacy","version":"0.1"}}}`, req.ID)
+		case "tools/list":
+			outbox <- `{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info","data":"hi"}}`
+			outbox <- fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{"tools":[]}}`, req.ID)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLegacyTransportExplicit(t *testing.T) {
+	server := newLegacyServer(t, "/sse")
+
+	c, err := New(Options{
+		Endpoint: server.URL + "/sse",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Timeout:  2 * time.Second,
+		Stream:   true,
+		HTTPMode: HTTPModeLegacySSE,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	result, err := c.Initialize(context.Background())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo.Name != "legacy" {
+		t.Errorf("expected server name %q, got %q", "legacy", result.ServerInfo.Name)
+	}
+
+	var events int
+	resp, err := c.Request(context.Background(), "tools/list", nil, func(protocol.Message) error {
+		events++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Result), `"tools":[]`) {
+		t.Errorf("unexpected result: %s", resp.Result)
+	}
+	if events != 1 {
+		t.Errorf("expected 1 streamed notification, got %d", events)
+	}
+}
+
+func TestLegacyTransportAutoFallback(t *testing.T) {
+	server := newLegacyServer(t, "/")
+
+	var logs []string
+	c, err := New(Options{
+		Endpoint: server.URL + "/",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Timeout:  2 * time.Second,
+		Logger: func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if _, ok := c.transport.(*LegacyTransport); !ok {
+		t.Errorf("expected fallback to LegacyTransport, got %T", c.transport)
+	}
+	if !strings.Contains(strings.Join(logs, "\n"), "falling back to HTTP+SSE") {
+		t.Errorf("expected fallback to be logged, got %v", logs)
+	}
+}
+
+func TestLegacyTransportNoFallbackInStreamableMode(t *testing.T) {
+	server := newLegacyServer(t, "/")
+
+	c, err := New(Options{Endpoint: server.URL + "/", Timeout: 2 * time.Second, HTTPMode: HTTPModeStreamable})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Initialize(context.Background()); err == nil {
+		t.Fatal("expected initialize to fail without fallback")
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		base, endpoint, expected string
+	}{
+		{"http://host:3000/sse", "/messages?sessionId=1", "http://host:3000/messages?sessionId=1"},
+		{"http://host:3000/mcp/sse", "messages", "http://host:3000/mcp/messages"},
+		{"http://host:3000/sse", "http://other:4000/msg", "http://other:4000/msg"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveEndpoint(tt.base, tt.endpoint)
+		if err != nil {
+			t.Fatalf("resolveEndpoint(%q, %q) failed: %v", tt.base, tt.endpoint, err)
+		}
+		if got != tt.expected {
+			t.Errorf("resolveEndpoint(%q, %q) = %q, expected %q", tt.base, tt.endpoint, got, tt.expected)
+		}
+	}
+}
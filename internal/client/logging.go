@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+var ErrLoggingNotSupported = errors.New("server did not advertise the logging capability")
+
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	if !protocol.IsLogLevel(level) {
+		return fmt.Errorf("invalid log level %q (expected one of: %s)", level, strings.Join(protocol.LogLevels, ", "))
+	}
+	if caps := c.session.Capabilities; caps == nil {
+		c.logf("* Server capabilities unknown, skipping the logging capability check")
+	} else if caps.Logging == nil {
+		return ErrLoggingNotSupported
+	}
+
+	raw, err := json.Marshal(protocol.SetLevelParams{Level: level})
+	if err != nil {
+		return err
+	}
+	if _, err := c.Request(ctx, "logging/setLevel", raw, nil); err != nil {
+		return err
+	}
+	c.logf("* Server log level set to %s", level)
+	return nil
+}
+
+func (c *Client) logFilter(onEvent func(protocol.Message) error, stream bool) (func(protocol.Message) error, bool) {
+	if c.opts.OnLogMessage == nil {
+		return onEvent, stream
+	}
+	return func(msg protocol.Message) error {
+		if c.handleLogMessage(msg) {
+			return nil
+		}
+		if stream && onEvent != nil {
+			return onEvent(msg)
+		}
+		return nil
+	}, true
+}
+
+func (c *Client) handleLogMessage(msg protocol.Message) bool {
+	if c.opts.OnLogMessage == nil || msg.Method != "notifications/message" {
+		return false
+	}
+	var params protocol.LoggingMessageParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		c.logf("* Malformed notifications/message: %v", err)
+		return false
+	}
+	c.opts.OnLogMessage(params)
+	return true
+}
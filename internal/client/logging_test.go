@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newLoggingServer(t *testing.T, capabilities string, level *string) *httptest.Server {
+	t.Helper()
+	return newMCPServer(t, map[string]mcpHandler{
+		"initialize": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			writeEvents(w,
+				`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info","data":"starting"}}`,
+				resultMessage(msg.ID, initializeResult("2025-06-18", capabilities)),
+			)
+		},
+		"logging/setLevel": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			var params protocol.SetLevelParams
+			json.Unmarshal(msg.Params, &params)
+			*level = params.Level
+			writeResult(w, msg.ID, `{}`)
+		},
+		"tools/call": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			writeEvents(w,
+				`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"error","logger":"db","data":{"error":"timeout"}}}`,
+				`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a"}}`,
+				resultMessage(msg.ID, `{"content":[]}`),
+			)
+		},
+	})
+}
+
+func TestClientLogMessages(t *testing.T) {
+	var level string
+	server := newLoggingServer(t, `{"logging":{},"tools":{}}`, &level)
+
+	var logs []protocol.LoggingMessageParams
+	c, err := New(Options{
+		Endpoint:     server.URL,
+		Timeout:      time.Second,
+		OnLogMessage: func(p protocol.LoggingMessageParams) { logs = append(logs, p) },
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := c.SetLogLevel(ctx, protocol.LogLevelDebug); err != nil {
+		t.Fatalf("SetLogLevel failed: %v", err)
+	}
+	if level != protocol.LogLevelDebug {
+		t.Errorf("expected logging/setLevel with debug, got %q", level)
+	}
+
+	var events []string
+	_, err = c.Request(ctx, "tools/call", json.RawMessage(`{"name":"query"}`), func(msg protocol.Message) error {
+		events = append(events, msg.Method)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 log messages, got %+v", logs)
+	}
+	if logs[0].Level != "info" || string(logs[0].Data) != `"starting"` {
+		t.Errorf("unexpected initialize log %+v", logs[0])
+	}
+	if logs[1].Level != "error" || logs[1].Logger != "db" || string(logs[1].Data) != `{"error":"timeout"}` {
+		t.Errorf("unexpected request log %+v", logs[1])
+	}
+	if len(events) != 0 {
+		t.Errorf("expected other notifications to stay hidden without --stream, got %v", events)
+	}
+}
+
+func TestClientLogMessagesWithStream(t *testing.T) {
+	var level string
+	server := newLoggingServer(t, `{"logging":{}}`, &level)
+
+	var logs int
+	c, err := New(Options{
+		Endpoint:     server.URL,
+		Timeout:      time.Second,
+		Stream:       true,
+		OnLogMessage: func(protocol.LoggingMessageParams) { logs++ },
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var events []string
+	_, err = c.Request(context.Background(), "tools/call", nil, func(msg protocol.Message) error {
+		events = append(events, msg.Method)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if logs != 1 {
+		t.Errorf("expected 1 log message, got %d", logs)
+	}
+	if len(events) != 1 || events[0] != "notifications/resources/updated" {
+		t.Errorf("expected only the non-log notification as an event, got %v", events)
+	}
+}
+
+func TestClientSetLogLevelNotAdvertised(t *testing.T) {
+	var level string
+	server := newLoggingServer(t, `{"tools":{}}`, &level)
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := c.SetLogLevel(context.Background(), protocol.LogLevelInfo); !errors.Is(err, ErrLoggingNotSupported) {
+		t.Errorf("expected ErrLoggingNotSupported, got %v", err)
+	}
+	if level != "" {
+		t.Error("expected no logging/setLevel request to be sent")
+	}
+}
+
+func TestClientSetLogLevelInvalid(t *testing.T) {
+	c, err := New(Options{Endpoint: "http://127.0.0.1:1/mcp", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := c.SetLogLevel(context.Background(), "verbose"); err == nil {
+		t.Error("expected error for an unknown log level")
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func parseEnvelope(body []byte) (id any, expectsResponse bool) {
+	var envelope struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, true
+	}
+	return envelope.ID, envelope.ID != nil && envelope.Method != ""
+}
+
+func parseMethod(body []byte) string {
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(body, &envelope)
+	return envelope.Method
+}
+
+func isAwaitedResponse(msg *protocol.Message, id any) bool {
+	return msg.IsResponse() && (id == nil || msg.ID == nil || protocol.SameID(msg.ID, id))
+}
+
+func route(msg protocol.Message, stream bool, onEvent func(protocol.Message) error, onRequest func(protocol.Message)) error {
+	if msg.IsRequest() && onRequest != nil {
+		onRequest(msg)
+		return nil
+	}
+	if stream && onEvent != nil {
+		return onEvent(msg)
+	}
+	return nil
+}
+
+type responseMatcher interface {
+	accept(msg *protocol.Message) bool
+	done() bool
+}
+
+type singleResponse struct {
+	id       any
+	expected bool
+	response *protocol.Response
+}
+
+func newSingleResponse(body []byte) *singleResponse {
+	id, expected := parseEnvelope(body)
+	return &singleResponse{id: id, expected: expected}
+}
+
+func (s *singleResponse) accept(msg *protocol.Message) bool {
+	if s.response != nil || !isAwaitedResponse(msg, s.id) {
+		return false
+	}
+	r := msg.Response()
+	s.response = &r
+	return true
+}
+
+func (s *singleResponse) done() bool {
+	return !s.expected || s.response != nil
+}
+
+type batchResponses struct {
+	ids       []any
+	responses []*protocol.Response
+	pending   int
+	rejected  *protocol.Error
+}
+
+func newBatchResponses(ids []any) *batchResponses {
+	return &batchResponses{
+		ids:       ids,
+		responses: make([]*protocol.Response, len(ids)),
+		pending:   len(ids),
+	}
+}
+
+func (b *batchResponses) accept(msg *protocol.Message) bool {
+	if !msg.IsResponse() {
+		return false
+	}
+	if msg.ID == nil && msg.Error != nil {
+		b.rejected = msg.Error
+		return true
+	}
+	for i, id := range b.ids {
+		if b.responses[i] == nil && protocol.SameID(id, msg.ID) {
+			r := msg.Response()
+			b.responses[i] = &r
+			b.pending--
+			return true
+		}
+	}
+	return false
+}
+
+func (b *batchResponses) done() bool {
+	return b.pending == 0 || b.rejected != nil
+}
+
+func (b *batchResponses) result() ([]*protocol.Response, error) {
+	if b.rejected != nil {
+		return nil, fmt.Errorf("batch rejected: %w", b.rejected)
+	}
+	return b.responses, nil
+}
+
+func parseBatchIDs(body []byte) ([]any, error) {
+	var envelopes []struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &envelopes); err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+	var ids []any
+	for _, e := range envelopes {
+		if e.ID != nil && e.Method != "" {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids, nil
+}
+
+func isBatch(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) > 0 && body[0] == '['
+}
+
+func decodeMessages(data []byte) ([]protocol.Message, error) {
+	if isBatch(data) {
+		var msgs []protocol.Message
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, err
+		}
+		return msgs, nil
+	}
+	var msg protocol.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return []protocol.Message{msg}, nil
+}
+
+func deliver(data []byte, m responseMatcher, stream bool, onEvent func(protocol.Message) error, onRequest func(protocol.Message)) error {
+	msgs, err := decodeMessages(data)
+	if err != nil {
+		return fmt.Errorf("invalid JSON message: %w", err)
+	}
+	for i := range msgs {
+		if m.accept(&msgs[i]) {
+			continue
+		}
+		if err := route(msgs[i], stream, onEvent, onRequest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func awaitResponses(ctx context.Context, messages <-chan []byte, closedErr func() error, m responseMatcher, timeout time.Duration, stream bool, onEvent func(protocol.Message) error, onRequest func(protocol.Message)) error {
+	if m.done() {
+		return nil
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case data, ok := <-messages:
+			if !ok {
+				return closedErr()
+			}
+			if err := deliver(data, m, stream, onEvent, onRequest); err != nil {
+				return err
+			}
+			if m.done() {
+				return nil
+			}
+		case <-deadline:
+			return fmt.Errorf("no response after %s", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func listenMessages(ctx context.Context, messages <-chan []byte, closedErr func() error, onMessage func(json.RawMessage) error, onRequest func(protocol.Message)) error {
+	for {
+		select {
+		case data, ok := <-messages:
+			if !ok {
+				return closedErr()
+			}
+			if err := dispatchListened(data, onMessage, onRequest); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func dispatchListened(data []byte, onMessage func(json.RawMessage) error, onRequest func(protocol.Message)) error {
+	if err := onMessage(json.RawMessage(data)); err != nil {
+		return err
+	}
+
+	var msg protocol.Message
+	if err := json.Unmarshal(data, &msg); err == nil && msg.IsRequest() && onRequest != nil {
+		onRequest(msg)
+	}
+	return nil
+}
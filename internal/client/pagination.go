@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+var ErrMaxPages = errors.New("page limit reached")
+
+func (c *Client) RequestAllPages(ctx context.Context, method string, params json.RawMessage, maxPages int, onEvent func(protocol.Message) error, opts ...RequestOption) (*protocol.Response, error) {
+	var merged map[string]json.RawMessage
+	var last *protocol.Response
+	seen := make(map[string]bool)
+	cursor := ""
+
+	for page := 1; ; page++ {
+		pageParams := params
+		if cursor != "" {
+			c.logf("* Fetching page %d of %s (cursor %s)", page, method, cursor)
+			var err error
+			if pageParams, err = withCursor(params, cursor); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.Request(ctx, method, pageParams, onEvent, opts...)
+		if err != nil {
+			return resp, err
+		}
+		if resp == nil {
+			return nil, fmt.Errorf("no response to %s on page %d", method, page)
+		}
+		last = resp
+
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse %s result on page %d: %w", method, page, err)
+		}
+		if merged == nil {
+			merged = result
+		} else {
+			mergePage(merged, result)
+		}
+
+		cursor = ""
+		if raw, ok := result["nextCursor"]; ok {
+			json.Unmarshal(raw, &cursor)
+		}
+		if cursor == "" {
+			delete(merged, "nextCursor")
+			break
+		}
+		if seen[cursor] {
+			return nil, fmt.Errorf("cursor loop detected: server returned cursor %q twice", cursor)
+		}
+		seen[cursor] = true
+
+		if maxPages > 0 && page >= maxPages {
+			merged["nextCursor"], _ = json.Marshal(cursor)
+			resp, err := mergedResponse(last, merged)
+			if err != nil {
+				return nil, err
+			}
+			return resp, fmt.Errorf("%w: stopped after %d pages of %s, next cursor %q", ErrMaxPages, page, method, cursor)
+		}
+	}
+
+	return mergedResponse(last, merged)
+}
+
+func withCursor(params json.RawMessage, cursor string) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &fields); err != nil {
+			return nil, fmt.Errorf("params must be an object to add a cursor: %w", err)
+		}
+	}
+
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, err
+	}
+	fields["cursor"] = raw
+	return json.Marshal(fields)
+}
+
+func mergePage(merged, page map[string]json.RawMessage) {
+	for key, value := range page {
+		if key == "nextCursor" || key == "_meta" {
+			continue
+		}
+
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+
+		var a, b []json.RawMessage
+		if json.Unmarshal(existing, &a) != nil || json.Unmarshal(value, &b) != nil {
+			continue
+		}
+		if combined, err := json.Marshal(append(a, b...)); err == nil {
+			merged[key] = combined
+		}
+	}
+}
+
+func mergedResponse(last *protocol.Response, merged map[string]json.RawMessage) (*protocol.Response, error) {
+	result, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	resp := *last
+	resp.Result = result
+	return &resp, nil
+}
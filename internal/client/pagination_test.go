@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newPagedServer(t *testing.T, pages map[string]string, cursors *[]string) *Client {
+	t.Helper()
+	server := newMCPServer(t, map[string]mcpHandler{
+		anyMethod: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			var params struct {
+				Cursor string `json:"cursor"`
+				Filter string `json:"filter"`
+			}
+			json.Unmarshal(msg.Params, &params)
+			*cursors = append(*cursors, params.Cursor+"/"+params.Filter)
+			writeResult(w, msg.ID, pages[params.Cursor])
+		},
+	})
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return c
+}
+
+func TestClientRequestAllPages(t *testing.T) {
+	var cursors []string
+	c := newPagedServer(t, map[string]string{
+		"":   `{"tools":[{"name":"a"}],"nextCursor":"c1","_meta":{"page":1}}`,
+		"c1": `{"tools":[{"name":"b"},{"name":"c"}],"nextCursor":"c2"}`,
+		"c2": `{"tools":[{"name":"d"}]}`,
+	}, &cursors)
+
+	resp, err := c.RequestAllPages(context.Background(), "tools/list", json.RawMessage(`{"filter":"x"}`), 10, nil)
+	if err != nil {
+		t.Fatalf("RequestAllPages failed: %v", err)
+	}
+
+	expected := `{"_meta":{"page":1},"tools":[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"}]}`
+	if string(resp.Result) != expected {
+		t.Errorf("expected %s, got %s", expected, resp.Result)
+	}
+	if strings.Join(cursors, " ") != "/x c1/x c2/x" {
+		t.Errorf("expected cursors to be sent with the original params, got %v", cursors)
+	}
+}
+
+func TestClientRequestAllPagesMaxPages(t *testing.T) {
+	var cursors []string
+	c := newPagedServer(t, map[string]string{
+		"":   `{"resources":[{"uri":"a"}],"nextCursor":"c1"}`,
+		"c1": `{"resources":[{"uri":"b"}],"nextCursor":"c2"}`,
+	}, &cursors)
+
+	resp, err := c.RequestAllPages(context.Background(), "resources/list", nil, 2, nil)
+	if !errors.Is(err, ErrMaxPages) {
+		t.Fatalf("expected ErrMaxPages, got %v", err)
+	}
+	expected := `{"nextCursor":"c2","resources":[{"uri":"a"},{"uri":"b"}]}`
+	if resp == nil || string(resp.Result) != expected {
+		t.Errorf("expected partial result %s, got %+v", expected, resp)
+	}
+}
+
+func TestClientRequestAllPagesCursorLoop(t *testing.T) {
+	var cursors []string
+	c := newPagedServer(t, map[string]string{
+		"":   `{"prompts":[],"nextCursor":"c1"}`,
+		"c1": `{"prompts":[],"nextCursor":"c1"}`,
+	}, &cursors)
+
+	_, err := c.RequestAllPages(context.Background(), "prompts/list", nil, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "cursor loop") {
+		t.Errorf("expected cursor loop error, got %v", err)
+	}
+	if len(cursors) != 2 {
+		t.Errorf("expected 2 requests before detecting the loop, got %d", len(cursors))
+	}
+}
+
+func TestClientRequestAllPagesNoResponse(t *testing.T) {
+	server := newMCPServer(t, map[string]mcpHandler{
+		"tools/list": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			w.WriteHeader(http.StatusAccepted)
+		},
+	})
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, err = c.RequestAllPages(context.Background(), "tools/list", nil, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "no response to tools/list on page 1") {
+		t.Errorf("expected missing response error, got %v", err)
+	}
+}
+
+func TestWithCursor(t *testing.T) {
+	params, err := withCursor(nil, "abc")
+	if err != nil || string(params) != `{"cursor":"abc"}` {
+		t.Errorf("withCursor(nil) = %s, %v", params, err)
+	}
+	if _, err := withCursor(json.RawMessage(`[1]`), "abc"); err == nil {
+		t.Error("expected error for non-object params")
+	}
+}
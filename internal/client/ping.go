@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+)
+
+func (c *Client) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if _, err := c.Request(ctx, "ping", nil, nil); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+type PingStats struct {
+	Sent int
+	RTTs []time.Duration
+}
+
+func (s *PingStats) Add(rtt time.Duration, err error) {
+	s.Sent++
+	if err == nil {
+		s.RTTs = append(s.RTTs, rtt)
+	}
+}
+
+func (s *PingStats) Received() int {
+	return len(s.RTTs)
+}
+
+func (s *PingStats) Failed() int {
+	return s.Sent - len(s.RTTs)
+}
+
+func (s *PingStats) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Failed()) / float64(s.Sent) * 100
+}
+
+func (s *PingStats) Min() time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	return slices.Min(s.RTTs)
+}
+
+func (s *PingStats) Max() time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	return slices.Max(s.RTTs)
+}
+
+func (s *PingStats) Avg() time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, rtt := range s.RTTs {
+		sum += rtt
+	}
+	return sum / time.Duration(len(s.RTTs))
+}
+
+func (s *PingStats) StdDev() time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	avg := float64(s.Avg())
+	var variance float64
+	for _, rtt := range s.RTTs {
+		d := float64(rtt) - avg
+		variance += d * d
+	}
+	return time.Duration(math.Sqrt(variance / float64(len(s.RTTs))))
+}
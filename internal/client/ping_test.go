@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientPing(t *testing.T) {
+	var pings int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "ping" {
+			t.Errorf("expected ping, got %s", req.Method)
+		}
+		pings++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{}}`, req.ID)
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	rtt, err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if rtt <= 0 {
+		t.Errorf("expected a positive round-trip time, got %v", rtt)
+	}
+	if pings != 1 {
+		t.Errorf("expected 1 ping, got %d", pings)
+	}
+}
+
+func TestPingStats(t *testing.T) {
+	var s PingStats
+	s.Add(10*time.Millisecond, nil)
+	s.Add(0, errors.New("timeout"))
+	s.Add(20*time.Millisecond, nil)
+	s.Add(30*time.Millisecond, nil)
+
+	if s.Sent != 4 || s.Received() != 3 || s.Failed() != 1 {
+		t.Errorf("expected 4 sent, 3 received, 1 failed, got %d/%d/%d", s.Sent, s.Received(), s.Failed())
+	}
+	if s.Loss() != 25 {
+		t.Errorf("expected 25%% loss, got %v", s.Loss())
+	}
+	if s.Min() != 10*time.Millisecond || s.Max() != 30*time.Millisecond || s.Avg() != 20*time.Millisecond {
+		t.Errorf("unexpected min/avg/max %v/%v/%v", s.Min(), s.Avg(), s.Max())
+	}
+	if got := s.StdDev().Round(time.Microsecond); got != 8165*time.Microsecond {
+		t.Errorf("expected stddev 8.165ms, got %v", got)
+	}
+}
+
+func TestPingStatsEmpty(t *testing.T) {
+	var s PingStats
+	if s.Loss() != 0 || s.Min() != 0 || s.Max() != 0 || s.Avg() != 0 || s.StdDev() != 0 {
+		t.Error("expected zero statistics without pings")
+	}
+	s.Add(0, errors.New("refused"))
+	if s.Loss() != 100 || s.Avg() != 0 {
+		t.Errorf("expected 100%% loss and no average, got %v and %v", s.Loss(), s.Avg())
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+type RetryPolicy struct {
+	MaxRetries int
+	MaxTime    time.Duration
+	RetryAll   bool
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var retryableStatus = map[int]bool{
+	http.StatusRequestTimeout:      true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "initialize", "notifications/initialized", "ping", "resources/read", "prompts/get":
+		return true
+	}
+	return strings.HasSuffix(method, "/list")
+}
+
+func (p RetryPolicy) allows(method string) bool {
+	return p.MaxRetries > 0 && (p.RetryAll || isIdempotent(method))
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := base
+	for i := 0; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func (t *Transport) postWithRetry(ctx context.Context, body []byte, send func() (*http.Response, error)) (*http.Response, error) {
+	method := parseMethod(body)
+	start := time.Now()
+
+	for retry := 0; ; retry++ {
+		resp, err := send()
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		var reason string
+		delay := t.retry.backoff(retry)
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case retryableStatus[resp.StatusCode]:
+			reason = "HTTP " + strconv.Itoa(resp.StatusCode)
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = d
+			}
+		default:
+			return resp, nil
+		}
+
+		if !t.retry.allows(method) {
+			if t.retry.MaxRetries > 0 {
+				t.logf("* Not retrying %s after %s: not idempotent (use --retry-all)", method, reason)
+			}
+			return resp, err
+		}
+		if retry >= t.retry.MaxRetries {
+			t.logf("* Giving up on %s after %d retries: %s", method, retry, reason)
+			return resp, err
+		}
+		if t.retry.MaxTime > 0 && time.Since(start)+delay > t.retry.MaxTime {
+			t.logf("* Giving up on %s: next retry in %s would exceed --retry-max-time %s", method, delay, t.retry.MaxTime)
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t.logf("* %s failed (%s), retry %d/%d in %s", method, reason, retry+1, t.retry.MaxRetries, delay.Round(time.Millisecond))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
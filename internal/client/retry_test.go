@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(t *testing.T, failures int, status int, retryAfter string) (*httptest.Server, *int) {
+	t.Helper()
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			http.Error(w, "unavailable", status)
+			return
+		}
+		var req struct {
+			ID any `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{}}`, req.ID)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestTransportRetriesIdempotentRequests(t *testing.T) {
+	server, calls := newFlakyServer(t, 2, http.StatusBadGateway, "")
+
+	var logs []string
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond})
+	tr.SetLogger(func(format string, args ...any) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+
+	resp, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), false, nil)
+	if err != nil {
+		t.Fatalf("PostAndReadResponse failed: %v", err)
+	}
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", *calls)
+	}
+	if len(logs) != 2 || !strings.Contains(logs[0], "HTTP 502") || !strings.Contains(logs[1], "retry 2/3") {
+		t.Errorf("unexpected retry logs: %v", logs)
+	}
+}
+
+func TestTransportRetryGivesUp(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusServiceUnavailable, "")
+
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	_, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected HTTP 503 error, got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestTransportRetrySkipsNonIdempotent(t *testing.T) {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"charge"}}`)
+
+	server, calls := newFlakyServer(t, 1, http.StatusBadGateway, "")
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond})
+	if _, _, err := tr.PostAndReadResponse(context.Background(), body, false, nil); err == nil {
+		t.Error("expected tools/call to fail without retry")
+	}
+	if *calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", *calls)
+	}
+
+	server, calls = newFlakyServer(t, 1, http.StatusBadGateway, "")
+	tr = NewTransport(server.URL, time.Second)
+	tr.SetRetryPolicy(RetryPolicy{MaxRetries: 3, RetryAll: true, BaseDelay: time.Millisecond})
+	if _, _, err := tr.PostAndReadResponse(context.Background(), body, false, nil); err != nil {
+		t.Errorf("expected tools/call to succeed with RetryAll, got %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", *calls)
+	}
+}
+
+func TestTransportRetryMaxTime(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusTooManyRequests, "60")
+
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetRetryPolicy(RetryPolicy{MaxRetries: 5, MaxTime: time.Second, BaseDelay: time.Millisecond})
+
+	start := time.Now()
+	_, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`), false, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if *calls != 1 {
+		t.Errorf("expected Retry-After beyond max time to stop retries, got %d attempts", *calls)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected no wait, took %s", time.Since(start))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"5", 5 * time.Second, true},
+		{"0", 0, true},
+		{"Wed, 01 Jan 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 Jan 2025 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; expected %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for retry, limit := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		limit *= time.Millisecond
+		for range 20 {
+			d := p.backoff(retry)
+			if d < limit/2 || d > limit {
+				t.Errorf("backoff(%d) = %s, expected within [%s, %s]", retry, d, limit/2, limit)
+			}
+		}
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	for method, want := range map[string]bool{
+		"initialize":     true,
+		"ping":           true,
+		"tools/list":     true,
+		"resources/list": true,
+		"resources/read": true,
+		"tools/call":     false,
+		"":               false,
+	} {
+		if got := isIdempotent(method); got != want {
+			t.Errorf("isIdempotent(%q) = %v, expected %v", method, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+var ErrRootsNotDeclared = errors.New("client capabilities do not declare roots")
+
+func NewRoot(pathOrURI string) (protocol.Root, error) {
+	if strings.Contains(pathOrURI, "://") {
+		u, err := url.Parse(pathOrURI)
+		if err != nil {
+			return protocol.Root{}, fmt.Errorf("invalid root URI %q: %w", pathOrURI, err)
+		}
+		if u.Scheme != "file" {
+			return protocol.Root{}, fmt.Errorf("root URI %q must use the file:// scheme", pathOrURI)
+		}
+		return protocol.Root{URI: pathOrURI, Name: path.Base(u.Path)}, nil
+	}
+
+	abs, err := filepath.Abs(pathOrURI)
+	if err != nil {
+		return protocol.Root{}, fmt.Errorf("invalid root path %q: %w", pathOrURI, err)
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	return protocol.Root{URI: u.String(), Name: filepath.Base(abs)}, nil
+}
+
+func (c *Client) Roots() []protocol.Root {
+	c.rootsMu.Lock()
+	defer c.rootsMu.Unlock()
+	if c.roots == nil {
+		return []protocol.Root{}
+	}
+	return slices.Clone(c.roots)
+}
+
+func (c *Client) SetRoots(ctx context.Context, roots []protocol.Root) error {
+	caps := c.opts.clientCapabilities()
+	if caps.Roots == nil {
+		return ErrRootsNotDeclared
+	}
+
+	c.rootsMu.Lock()
+	c.roots = slices.Clone(roots)
+	c.rootsMu.Unlock()
+	c.logf("* Roots changed (%d)", len(roots))
+
+	if !caps.Roots.ListChanged {
+		c.logf("* roots.listChanged not declared, not sending notifications/roots/list_changed")
+		return nil
+	}
+	if err := c.notify(ctx, "notifications/roots/list_changed", nil); err != nil {
+		return fmt.Errorf("failed to send notifications/roots/list_changed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func TestNewRoot(t *testing.T) {
+	abs, _ := filepath.Abs("testdata/my project")
+	tests := []struct {
+		in   string
+		want protocol.Root
+	}{
+		{"/srv/data", protocol.Root{URI: "file:///srv/data", Name: "data"}},
+		{"testdata/my project", protocol.Root{URI: "file://" + filepath.ToSlash(filepath.Dir(abs)) + "/my%20project", Name: "my project"}},
+		{"file:///home/user/repo", protocol.Root{URI: "file:///home/user/repo", Name: "repo"}},
+	}
+	for _, tt := range tests {
+		got, err := NewRoot(tt.in)
+		if err != nil {
+			t.Errorf("NewRoot(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got.URI != tt.want.URI || got.Name != tt.want.Name {
+			t.Errorf("NewRoot(%q) = %+v, expected %+v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := NewRoot("https://example.com/repo"); err == nil {
+		t.Error("expected error for a non-file root URI")
+	}
+}
+
+type rootsServer struct {
+	mu      sync.Mutex
+	methods []string
+	replies []protocol.ListRootsResult
+}
+
+func newRootsServer(t *testing.T) (*httptest.Server, *rootsServer) {
+	t.Helper()
+	rs := &rootsServer{}
+	server := newMCPServer(t, map[string]mcpHandler{
+		clientReply: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			var result protocol.ListRootsResult
+			json.Unmarshal(msg.Result, &result)
+			rs.mu.Lock()
+			rs.replies = append(rs.replies, result)
+			rs.mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		},
+		anyMethod: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			rs.mu.Lock()
+			rs.methods = append(rs.methods, msg.Method)
+			rs.mu.Unlock()
+			if msg.ID == nil {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			writeEvents(w,
+				`{"jsonrpc":"2.0","id":"srv-1","method":"roots/list"}`,
+				resultMessage(msg.ID, `{}`),
+			)
+		},
+	})
+	return server, rs
+}
+
+func TestClientRootsList(t *testing.T) {
+	server, rs := newRootsServer(t)
+
+	c, err := New(Options{
+		Endpoint: server.URL,
+		Timeout:  time.Second,
+		Roots:    []protocol.Root{{URI: "file:///srv/a", Name: "a"}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.Request(ctx, "tools/call", nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if err := c.SetRoots(ctx, []protocol.Root{{URI: "file:///srv/b", Name: "b"}}); err != nil {
+		t.Fatalf("SetRoots failed: %v", err)
+	}
+	if _, err := c.Request(ctx, "tools/call", nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	expected := []string{"tools/call", "notifications/roots/list_changed", "tools/call"}
+	if fmt.Sprint(rs.methods) != fmt.Sprint(expected) {
+		t.Errorf("expected methods %v, got %v", expected, rs.methods)
+	}
+	if len(rs.replies) != 2 {
+		t.Fatalf("expected 2 roots/list replies, got %d", len(rs.replies))
+	}
+	if len(rs.replies[0].Roots) != 1 || rs.replies[0].Roots[0].URI != "file:///srv/a" {
+		t.Errorf("expected initial roots, got %+v", rs.replies[0])
+	}
+	if len(rs.replies[1].Roots) != 1 || rs.replies[1].Roots[0].URI != "file:///srv/b" {
+		t.Errorf("expected updated roots, got %+v", rs.replies[1])
+	}
+}
+
+func TestClientSetRootsWithoutListChanged(t *testing.T) {
+	server, rs := newRootsServer(t)
+
+	c, err := New(Options{
+		Endpoint:     server.URL,
+		Timeout:      time.Second,
+		Capabilities: &protocol.ClientCapabilities{Roots: &protocol.RootsCapability{}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := c.SetRoots(context.Background(), []protocol.Root{{URI: "file:///srv/a"}}); err != nil {
+		t.Fatalf("SetRoots failed: %v", err)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if len(rs.methods) != 0 {
+		t.Errorf("expected no notification without roots.listChanged, got %v", rs.methods)
+	}
+	if roots := c.Roots(); len(roots) != 1 {
+		t.Errorf("expected roots to be updated, got %+v", roots)
+	}
+}
+
+func TestClientSetRootsNotDeclared(t *testing.T) {
+	server, rs := newRootsServer(t)
+
+	c, err := New(Options{
+		Endpoint:     server.URL,
+		Timeout:      time.Second,
+		Capabilities: &protocol.ClientCapabilities{},
+		Roots:        []protocol.Root{{URI: "file:///srv/a"}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	err = c.SetRoots(context.Background(), []protocol.Root{{URI: "file:///srv/b"}})
+	if !errors.Is(err, ErrRootsNotDeclared) {
+		t.Fatalf("expected ErrRootsNotDeclared, got %v", err)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if len(rs.methods) != 0 {
+		t.Errorf("expected no notification without the roots capability, got %v", rs.methods)
+	}
+	if roots := c.Roots(); len(roots) != 1 || roots[0].URI != "file:///srv/a" {
+		t.Errorf("expected roots to stay unchanged, got %+v", roots)
+	}
+}
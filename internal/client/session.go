@@ -3,9 +3,10 @@ package client
 import "github.com/bigbag/mcpsnag/internal/protocol"
 
 type Session struct {
-	ID           string
-	Capabilities *protocol.ServerCapabilities
-	ServerInfo   *protocol.Implementation
+	ID              string
+	ProtocolVersion string
+	Capabilities    *protocol.ServerCapabilities
+	ServerInfo      *protocol.Implementation
 }
 
 func (s *Session) IsValid() bool {
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxEventSize = 32 << 20
+	defaultSSERetry     = time.Second
+	maxSeenEventIDs     = 1024
+)
+
+var ErrEventTooLarge = errors.New("SSE event exceeds maximum size")
+
+type SSEEvent struct {
+	Event string
+	Data  string
+	ID    string
+}
+
+type SSEParser struct {
+	maxEventSize int
+	lastEventID  string
+	retry        time.Duration
+}
+
+func NewSSEParser(maxEventSize int) *SSEParser {
+	if maxEventSize <= 0 {
+		maxEventSize = DefaultMaxEventSize
+	}
+	return &SSEParser{maxEventSize: maxEventSize, retry: defaultSSERetry}
+}
+
+func (p *SSEParser) LastEventID() string {
+	return p.lastEventID
+}
+
+func (p *SSEParser) Retry() time.Duration {
+	return p.retry
+}
+
+func (p *SSEParser) Parse(r io.Reader, handler func(event SSEEvent) error) error {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+
+	var (
+		line      []byte
+		data      strings.Builder
+		eventType string
+		eventID   = p.lastEventID
+		hasID     bool
+		afterCR   bool
+	)
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if b == '\n' && afterCR {
+			afterCR = false
+			continue
+		}
+		afterCR = b == '\r'
+		if b != '\r' && b != '\n' {
+			if data.Len()+len(line) >= p.maxEventSize {
+				return fmt.Errorf("%w (%d bytes)", ErrEventTooLarge, p.maxEventSize)
+			}
+			line = append(line, b)
+			continue
+		}
+
+		if len(line) == 0 {
+			p.lastEventID = eventID
+			if data.Len() > 0 {
+				event := SSEEvent{
+					Event: eventType,
+					Data:  strings.TrimSuffix(data.String(), "\n"),
+				}
+				if hasID {
+					event.ID = eventID
+				}
+				if err := handler(event); err != nil {
+					return err
+				}
+			}
+			data.Reset()
+			eventType = ""
+			hasID = false
+			continue
+		}
+
+		field, value := string(line), ""
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field = string(line[:i])
+			value = strings.TrimPrefix(string(line[i+1:]), " ")
+		}
+		line = line[:0]
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				eventID = value
+				hasID = true
+			}
+		case "retry":
+			if ms, ok := parseRetry(value); ok {
+				p.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+func parseRetry(value string) (int64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return 0, false
+		}
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	return ms, err == nil
+}
+
+func ParseSSEStream(r io.Reader, handler func(event SSEEvent) error) error {
+	return NewSSEParser(0).Parse(r, handler)
+}
+
+type sseStream struct {
+	parser     *SSEParser
+	seen       map[string]bool
+	seenOrder  []string
+	resuming   bool
+	handler    func(SSEEvent) error
+	handlerErr error
+}
+
+func newSSEStream(maxEventSize int, handler func(SSEEvent) error) *sseStream {
+	return &sseStream{
+		parser:  NewSSEParser(maxEventSize),
+		seen:    make(map[string]bool),
+		handler: handler,
+	}
+}
+
+func (s *sseStream) lastID() string {
+	return s.parser.LastEventID()
+}
+
+func (s *sseStream) retry() time.Duration {
+	return s.parser.Retry()
+}
+
+func (s *sseStream) resumed() {
+	s.resuming = true
+}
+
+func (s *sseStream) replayed(id string) bool {
+	if s.seen[id] {
+		return true
+	}
+	if s.resuming {
+		s.resuming = false
+		clear(s.seen)
+		s.seenOrder = s.seenOrder[:0]
+	}
+	if len(s.seenOrder) >= maxSeenEventIDs {
+		delete(s.seen, s.seenOrder[0])
+		s.seenOrder = s.seenOrder[1:]
+	}
+	s.seen[id] = true
+	s.seenOrder = append(s.seenOrder, id)
+	return false
+}
+
+func (s *sseStream) read(r io.Reader) error {
+	return s.parser.Parse(r, func(event SSEEvent) error {
+		if event.ID != "" && s.replayed(event.ID) {
+			return nil
+		}
+		if err := s.handler(event); err != nil {
+			s.handlerErr = err
+			return err
+		}
+		return nil
+	})
+}
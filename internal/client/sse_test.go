@@ -0,0 +1,281 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSSEStream(t *testing.T) {
+	input := `event: message
+data: {"jsonrpc":"2.0","id":1,"result":{"tools":[]}}
+
+event: message
+data: {"jsonrpc":"2.0","id":2,"result":{"done":true}}
+
+`
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if events[0].Event != "message" {
+		t.Errorf("expected event 'message', got %q", events[0].Event)
+	}
+	if !strings.Contains(events[0].Data, `"tools":[]`) {
+		t.Errorf("unexpected data: %s", events[0].Data)
+	}
+}
+
+func TestParseSSEStreamMultilineData(t *testing.T) {
+	input := `event: message
+data: line1
+data: line2
+
+`
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	expected := "line1\nline2"
+	if events[0].Data != expected {
+		t.Errorf("expected data %q, got %q", expected, events[0].Data)
+	}
+}
+
+func TestParseSSEStreamWithComments(t *testing.T) {
+	input := `: this is a comment
+event: message
+data: test
+
+`
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	if events[0].Data != "test" {
+		t.Errorf("expected data 'test', got %q", events[0].Data)
+	}
+}
+
+func TestParseSSEStreamWithID(t *testing.T) {
+	input := `event: message
+id: 123
+data: test
+
+`
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	if events[0].ID != "123" {
+		t.Errorf("expected ID '123', got %q", events[0].ID)
+	}
+}
+
+func TestParseSSEStreamNoEventType(t *testing.T) {
+	input := `data: {"result":"ok"}
+
+`
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	if events[0].Event != "" {
+		t.Errorf("expected empty event type, got %q", events[0].Event)
+	}
+}
+
+type sseCorpusEvent struct {
+	Event string `json:"event"`
+	Data  string `json:"data"`
+	ID    string `json:"id"`
+}
+
+type sseCorpusCase struct {
+	Events      []sseCorpusEvent `json:"events"`
+	LastEventID string           `json:"lastEventId"`
+	RetryMs     int              `json:"retryMs"`
+}
+
+func TestSSEParserCorpus(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "sse", "*.sse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no SSE corpus files found")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".sse")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedJSON, err := os.ReadFile(strings.TrimSuffix(input, ".sse") + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected sseCorpusCase
+			if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			got := sseCorpusCase{Events: []sseCorpusEvent{}}
+			p := NewSSEParser(0)
+			err = p.Parse(strings.NewReader(string(raw)), func(event SSEEvent) error {
+				got.Events = append(got.Events, sseCorpusEvent{event.Event, event.Data, event.ID})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			got.LastEventID = p.LastEventID()
+			got.RetryMs = int(p.Retry() / time.Millisecond)
+
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("got %+v, expected %+v", got, expected)
+			}
+		})
+	}
+}
+
+func TestSSEParserLargeEvent(t *testing.T) {
+	payload := strings.Repeat("x", 1<<20)
+	input := "data: " + payload + "\n\n"
+
+	var events []SSEEvent
+	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseSSEStream failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Data != payload {
+		t.Fatalf("expected one 1 MiB event, got %d events", len(events))
+	}
+}
+
+func TestSSEParserMaxEventSize(t *testing.T) {
+	input := "data: " + strings.Repeat("x", 100) + "\ndata: " + strings.Repeat("y", 100) + "\n\n"
+
+	err := NewSSEParser(150).Parse(strings.NewReader(input), func(SSEEvent) error {
+		t.Error("unexpected event")
+		return nil
+	})
+	if !errors.Is(err, ErrEventTooLarge) {
+		t.Errorf("expected ErrEventTooLarge, got %v", err)
+	}
+
+	err = NewSSEParser(250).Parse(strings.NewReader(input), func(SSEEvent) error { return nil })
+	if err != nil {
+		t.Errorf("expected event within limit to parse, got %v", err)
+	}
+}
+
+func TestSSEParserStateAcrossStreams(t *testing.T) {
+	p := NewSSEParser(0)
+	if err := p.Parse(strings.NewReader("retry: 50\nid: 3\ndata: a\n\n"), func(SSEEvent) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(strings.NewReader("data: b\n\n"), func(SSEEvent) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if p.LastEventID() != "3" {
+		t.Errorf("expected last event ID to survive reconnect, got %q", p.LastEventID())
+	}
+	if p.Retry() != 50*time.Millisecond {
+		t.Errorf("expected retry 50ms, got %s", p.Retry())
+	}
+}
+
+func TestSSEStreamSkipsReplayedEvents(t *testing.T) {
+	var ids []string
+	s := newSSEStream(0, func(event SSEEvent) error {
+		ids = append(ids, event.ID)
+		return nil
+	})
+
+	s.read(strings.NewReader("id: 1\ndata: a\n\nid: 2\ndata: b\n\n"))
+	s.resumed()
+	s.read(strings.NewReader("id: 1\ndata: a\n\nid: 2\ndata: b\n\nid: 3\ndata: c\n\n"))
+
+	if strings.Join(ids, " ") != "1 2 3" {
+		t.Errorf("expected replayed events to be delivered once, got %v", ids)
+	}
+	if len(s.seen) != 1 || !s.seen["3"] {
+		t.Errorf("expected only IDs after the resume point to be kept, got %v", s.seen)
+	}
+}
+
+func TestSSEStreamSeenIDsBounded(t *testing.T) {
+	s := newSSEStream(0, func(SSEEvent) error { return nil })
+
+	var b strings.Builder
+	for i := range 3 * maxSeenEventIDs {
+		fmt.Fprintf(&b, "id: %d\ndata: x\n\n", i)
+	}
+	s.read(strings.NewReader(b.String()))
+
+	if len(s.seen) != maxSeenEventIDs || len(s.seenOrder) != maxSeenEventIDs {
+		t.Errorf("expected %d remembered IDs, got %d (%d in order)", maxSeenEventIDs, len(s.seen), len(s.seenOrder))
+	}
+}
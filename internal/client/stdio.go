@@ -0,0 +1,188 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+const stdioShutdownGrace = 2 * time.Second
+
+type StdioTransport struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	timeout  time.Duration
+	writeMu  sync.Mutex
+	messages chan []byte
+	readErr  error
+	exited   chan struct{}
+	waitErr  error
+
+	onRequest func(protocol.Message)
+}
+
+func NewStdioTransport(command []string, stderr io.Writer, timeout time.Duration) (*StdioTransport, error) {
+	if len(command) == 0 {
+		return nil, errors.New("stdio command is required")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = stderr
+	detachProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = stdoutWriter
+
+	err = cmd.Start()
+	stdoutWriter.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+
+	t := &StdioTransport{
+		cmd:      cmd,
+		stdin:    stdin,
+		timeout:  timeout,
+		messages: make(chan []byte, 16),
+		exited:   make(chan struct{}),
+	}
+	go t.readLoop(stdout)
+	go t.wait()
+
+	return t, nil
+}
+
+func (t *StdioTransport) readLoop(r io.ReadCloser) {
+	defer close(t.messages)
+	defer r.Close()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			t.messages <- line
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.readErr = err
+			}
+			return
+		}
+	}
+}
+
+func (t *StdioTransport) wait() {
+	t.waitErr = t.cmd.Wait()
+	close(t.exited)
+}
+
+func (t *StdioTransport) SetHeader(key, value string) {}
+
+func (t *StdioTransport) SetRequestHandler(handler func(protocol.Message)) {
+	t.onRequest = handler
+}
+
+func (t *StdioTransport) write(body []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	msg := make([]byte, 0, len(body)+1)
+	msg = append(msg, bytes.TrimSpace(body)...)
+	msg = append(msg, '\n')
+
+	if _, err := t.stdin.Write(msg); err != nil {
+		return fmt.Errorf("failed to write to server: %w", err)
+	}
+	return nil
+}
+
+func (t *StdioTransport) PostAndReadResponse(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) (*protocol.Response, string, error) {
+	m := newSingleResponse(body)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	return m.response, sessionID, err
+}
+
+func (t *StdioTransport) PostBatch(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error) {
+	ids, err := parseBatchIDs(body)
+	if err != nil {
+		return nil, "", err
+	}
+	m := newBatchResponses(ids)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	if err != nil {
+		return nil, sessionID, err
+	}
+	responses, err := m.result()
+	return responses, sessionID, err
+}
+
+func (t *StdioTransport) exchange(ctx context.Context, body []byte, m responseMatcher, stream bool, onEvent func(protocol.Message) error) (string, error) {
+	if err := t.write(body); err != nil {
+		return "", err
+	}
+	return "", awaitResponses(ctx, t.messages, t.closedError, m, t.timeout, stream, onEvent, t.onRequest)
+}
+
+func (t *StdioTransport) Listen(ctx context.Context, onMessage func(json.RawMessage) error) error {
+	return listenMessages(ctx, t.messages, t.closedError, onMessage, t.onRequest)
+}
+
+func (t *StdioTransport) closedError() error {
+	if t.readErr != nil {
+		return fmt.Errorf("failed to read from server: %w", t.readErr)
+	}
+
+	select {
+	case <-t.exited:
+		if t.waitErr != nil {
+			return fmt.Errorf("server process exited: %w", t.waitErr)
+		}
+		return errors.New("server process exited")
+	case <-time.After(stdioShutdownGrace):
+		return errors.New("server closed stdout")
+	}
+}
+
+func (t *StdioTransport) Close() error {
+	t.stdin.Close()
+
+	select {
+	case <-t.exited:
+		return nil
+	case <-time.After(stdioShutdownGrace):
+	}
+
+	if err := t.cmd.Process.Signal(syscall.SIGTERM); err == nil {
+		select {
+		case <-t.exited:
+			return nil
+		case <-time.After(stdioShutdownGrace):
+		}
+	}
+
+	if err := t.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	<-t.exited
+	return nil
+}
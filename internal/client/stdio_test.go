@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func TestStdioHelperProcess(t *testing.T) {
+	if os.Getenv("MCPSNAG_STDIO_HELPER") != "1" {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "helper started")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if batch := scanner.Bytes(); batch[0] == '[' {
+			var reqs []protocol.Request
+			if err := json.Unmarshal(batch, &reqs); err != nil {
+				os.Exit(2)
+			}
+			var replies []string
+			for _, req := range reqs {
+				if req.ID != nil {
+					replies = append(replies, fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{"method":%q}}`, req.ID, req.Method))
+				}
+			}
+			slices.Reverse(replies)
+			fmt.Println("[" + strings.Join(replies, ",") + "]")
+			continue
+		}
+
+		var req protocol.Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+		if req.Method == "notifications/cancelled" {
+			fmt.Fprintf(os.Stderr, "cancelled %s\n", req.Params)
+		}
+		if req.ID == nil {
+			continue
+		}
+
+		switch req.Method {
+		case "initialize":
+			fmt.Printf(`{"jsonrpc":"2.0","id":%v,"result":{"protocolVersion":"2025-03-26","capabilities":{"tools":{}},"serverInfo":{"name":"helper","version":"0.1"}}}`+"\n", req.ID)
+		case "tools/list":
+			fmt.Println(`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info","data":"listing"}}`)
+			fmt.Printf(`{"jsonrpc":"2.0","id":%v,"result":{"tools":[]}}`+"\n", req.ID)
+		case "exit":
+			os.Exit(0)
+		case "slow":
+		default:
+			fmt.Printf(`{"jsonrpc":"2.0","id":%v,"error":{"code":-32601,"message":"Method not found"}}`+"\n", req.ID)
+		}
+	}
+	os.Exit(0)
+}
+
+func newHelperClient(t *testing.T, stderr *bytes.Buffer) *Client {
+	t.Helper()
+	t.Setenv("MCPSNAG_STDIO_HELPER", "1")
+
+	c, err := New(Options{
+		Command: []string{os.Args[0], "-test.run=TestStdioHelperProcess"},
+		Stderr:  stderr,
+		Timeout: 5 * time.Second,
+		Stream:  true,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return c
+}
+
+func TestStdioTransportInitializeAndRequest(t *testing.T) {
+	var stderr bytes.Buffer
+	c := newHelperClient(t, &stderr)
+
+	result, err := c.Initialize(context.Background())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo.Name != "helper" {
+		t.Errorf("expected server name %q, got %q", "helper", result.ServerInfo.Name)
+	}
+
+	var events int
+	resp, err := c.Request(context.Background(), "tools/list", nil, func(msg protocol.Message) error {
+		events++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Result), `"tools":[]`) {
+		t.Errorf("unexpected result: %s", resp.Result)
+	}
+	if events != 1 {
+		t.Errorf("expected 1 streamed notification, got %d", events)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "helper started") {
+		t.Errorf("expected child stderr to be forwarded, got %q", stderr.String())
+	}
+}
+
+func TestStdioTransportErrorResponse(t *testing.T) {
+	c := newHelperClient(t, &bytes.Buffer{})
+	defer c.Close()
+
+	_, err := c.Request(context.Background(), "unknown/method", nil, nil)
+	if err == nil {
+		t.Fatal("expected error for unknown method")
+	}
+	if err.Error() != "Method not found" {
+		t.Errorf("expected %q, got %q", "Method not found", err.Error())
+	}
+}
+
+func TestStdioTransportBatch(t *testing.T) {
+	c := newHelperClient(t, &bytes.Buffer{})
+	defer c.Close()
+
+	results, err := c.Batch(context.Background(), []protocol.UserRequest{
+		{Method: "tools/list"},
+		{Method: "notifications/roots/list_changed"},
+		{Method: "prompts/list"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Response == nil || string(r.Response.Result) != `{"method":"`+r.Method+`"}` {
+			t.Errorf("unexpected result for %s (id %v): %+v", r.Method, r.ID, r.Response)
+		}
+	}
+}
+
+func TestStdioTransportServerExit(t *testing.T) {
+	c := newHelperClient(t, &bytes.Buffer{})
+	defer c.Close()
+
+	_, err := c.Request(context.Background(), "exit", nil, nil)
+	if err == nil {
+		t.Fatal("expected error when server exits")
+	}
+	if !strings.Contains(err.Error(), "exited") {
+		t.Errorf("expected exit error, got %v", err)
+	}
+}
+
+func TestStdioTransportCancelReachesServer(t *testing.T) {
+	var stderr bytes.Buffer
+	c := newHelperClient(t, &stderr)
+
+	cause := errors.New("interrupted by user")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, cause)
+	defer cancel()
+
+	_, err := c.Request(ctx, "slow", nil, nil)
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected cancellation cause in error, got %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), `cancelled {"requestId":1,"reason":"interrupted by user"}`) {
+		t.Errorf("expected the server to receive notifications/cancelled, got %q", stderr.String())
+	}
+}
+
+func TestNewStdioTransportEmptyCommand(t *testing.T) {
+	if _, err := NewStdioTransport(nil, nil, time.Second); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package client
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func detachProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
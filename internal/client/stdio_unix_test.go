@@ -0,0 +1,23 @@
+//go:build unix
+
+package client
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+)
+
+func TestStdioTransportOwnProcessGroup(t *testing.T) {
+	c := newHelperClient(t, &bytes.Buffer{})
+	defer c.Close()
+
+	pid := c.transport.(*StdioTransport).cmd.Process.Pid
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil {
+		t.Fatalf("Getpgid failed: %v", err)
+	}
+	if pgid != pid || pgid == syscall.Getpgrp() {
+		t.Errorf("expected the server to lead its own process group, got pgid %d for pid %d", pgid, pid)
+	}
+}
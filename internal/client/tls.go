@@ -0,0 +1,105 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type TLSOptions struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	Insecure   bool
+	MinVersion string
+	ServerName string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func ParseTLSVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[strings.TrimPrefix(version, "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q (expected 1.0, 1.1, 1.2 or 1.3)", version)
+	}
+	return v, nil
+}
+
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		ServerName:         o.ServerName,
+	}
+
+	if o.MinVersion != "" {
+		v, err := ParseTLSVersion(o.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinVersion = v
+	}
+
+	if o.CertFile != "" {
+		keyFile := o.KeyFile
+		if keyFile == "" {
+			keyFile = o.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	} else if o.KeyFile != "" {
+		return nil, errors.New("a client key requires a client certificate")
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+func logTLSState(logf func(format string, args ...any), state *tls.ConnectionState) {
+	if state == nil {
+		return
+	}
+
+	line := fmt.Sprintf("* TLS %s, cipher %s", strings.TrimPrefix(tls.VersionName(state.Version), "TLS "), tls.CipherSuiteName(state.CipherSuite))
+	if state.NegotiatedProtocol != "" {
+		line += ", ALPN " + state.NegotiatedProtocol
+	}
+	if state.ServerName != "" {
+		line += ", SNI " + state.ServerName
+	}
+	logf("%s", line)
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		logf("* Server certificate: subject %q, issuer %q, expires %s", cert.Subject.String(), cert.Issuer.String(), cert.NotAfter.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pingOverTLS(t *testing.T, endpoint string, opts TLSOptions) error {
+	t.Helper()
+	cfg, err := opts.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	tr := NewTransport(endpoint, 5*time.Second)
+	tr.SetTLSConfig(cfg)
+	defer tr.Close()
+	_, _, err = tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+	return err
+}
+
+func TestTransportTLSOptions(t *testing.T) {
+	server := newTLSServer(t)
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	if err := pingOverTLS(t, server.URL, TLSOptions{MinVersion: "1.2"}); err == nil {
+		t.Error("expected untrusted certificate to fail verification")
+	}
+	if err := pingOverTLS(t, server.URL, TLSOptions{CAFile: caFile}); err != nil {
+		t.Errorf("expected custom CA bundle to verify server, got %v", err)
+	}
+	if err := pingOverTLS(t, server.URL, TLSOptions{Insecure: true}); err != nil {
+		t.Errorf("expected insecure mode to skip verification, got %v", err)
+	}
+	if err := pingOverTLS(t, server.URL, TLSOptions{CAFile: caFile, ServerName: "wrong.example"}); err == nil {
+		t.Error("expected SNI override to be used for verification")
+	}
+	if err := pingOverTLS(t, server.URL, TLSOptions{CAFile: caFile, ServerName: "example.com"}); err != nil {
+		t.Errorf("expected SNI override matching the certificate to succeed, got %v", err)
+	}
+}
+
+func TestTransportClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mcpsnag-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := writePEM(t, dir, "client.pem", "CERTIFICATE", der)
+	keyFile := writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	if err := pingOverTLS(t, server.URL, TLSOptions{Insecure: true}); err == nil {
+		t.Error("expected server to reject a client without a certificate")
+	}
+	if err := pingOverTLS(t, server.URL, TLSOptions{Insecure: true, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Errorf("expected client certificate to be accepted, got %v", err)
+	}
+}
+
+func TestTLSOptionsConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0o600)
+
+	tests := []TLSOptions{
+		{MinVersion: "1.4"},
+		{KeyFile: "client.key"},
+		{CertFile: filepath.Join(dir, "missing.pem")},
+		{CAFile: empty},
+	}
+	for _, opts := range tests {
+		if _, err := opts.Config(); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+
+	if cfg, err := (TLSOptions{}).Config(); cfg != nil || err != nil {
+		t.Errorf("expected nil config for zero options, got %v, %v", cfg, err)
+	}
+}
+
+func TestTransportLogsTLSDetails(t *testing.T) {
+	server := newTLSServer(t)
+
+	var logs []string
+	tr := NewTransport(server.URL, 5*time.Second)
+	tr.SetTLSConfig(&tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12})
+	tr.SetLogger(func(format string, args ...any) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+	for range 2 {
+		if _, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil); err != nil {
+			t.Fatalf("PostAndReadResponse failed: %v", err)
+		}
+	}
+
+	if len(logs) != 2 || !strings.HasPrefix(logs[0], "* TLS 1.") || !strings.Contains(logs[1], "Server certificate") {
+		t.Errorf("expected TLS details logged once, got %v", logs)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	for version, want := range map[string]uint16{"1.2": tls.VersionTLS12, "1.3": tls.VersionTLS13, "TLS1.0": tls.VersionTLS10} {
+		if got, err := ParseTLSVersion(version); err != nil || got != want {
+			t.Errorf("ParseTLSVersion(%q) = %v, %v", version, got, err)
+		}
+	}
+	if _, err := ParseTLSVersion("ssl3"); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func (c *Client) ToolOutputSchema(ctx context.Context, name string, maxPages int) (json.RawMessage, error) {
+	seen := make(map[string]bool)
+	var params json.RawMessage
+	for page := 1; ; page++ {
+		resp, err := c.Request(ctx, "tools/list", params, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			return nil, fmt.Errorf("no response to tools/list on page %d", page)
+		}
+
+		var result protocol.ListToolsResult
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse tools/list result: %w", err)
+		}
+		for _, tool := range result.Tools {
+			if tool.Name == name {
+				return tool.OutputSchema, nil
+			}
+		}
+
+		cursor := result.NextCursor
+		if cursor == "" {
+			return nil, nil
+		}
+		if seen[cursor] {
+			return nil, fmt.Errorf("cursor loop detected: server returned cursor %q twice", cursor)
+		}
+		seen[cursor] = true
+		if maxPages > 0 && page >= maxPages {
+			return nil, fmt.Errorf("%w: tool %s not found in the first %d pages of tools/list", ErrMaxPages, name, page)
+		}
+		if params, err = withCursor(nil, cursor); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) ReadResource(ctx context.Context, uri string) (*protocol.ReadResourceResult, error) {
+	params, err := json.Marshal(protocol.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Request(ctx, "resources/read", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response to resources/read")
+	}
+
+	var result protocol.ReadResourceResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse resources/read result: %w", err)
+	}
+	return &result, nil
+}
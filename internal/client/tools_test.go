@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newToolsServer(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	return newMCPServer(t, map[string]mcpHandler{
+		"tools/list": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			var params struct {
+				Cursor string `json:"cursor"`
+			}
+			json.Unmarshal(msg.Params, &params)
+			writeResult(w, msg.ID, pages[params.Cursor])
+		},
+		"resources/read": func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			var params protocol.ReadResourceParams
+			json.Unmarshal(msg.Params, &params)
+			writeResult(w, msg.ID, fmt.Sprintf(`{"contents":[{"uri":%q,"text":"hello"}]}`, params.URI))
+		},
+	})
+}
+
+func TestClientToolOutputSchema(t *testing.T) {
+	server := newToolsServer(t, map[string]string{
+		"":      `{"tools":[{"name":"echo","inputSchema":{"type":"object"}}],"nextCursor":"page2"}`,
+		"page2": `{"tools":[{"name":"weather","inputSchema":{"type":"object"},"outputSchema":{"type":"object"}}]}`,
+	})
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	schema, err := c.ToolOutputSchema(context.Background(), "weather", 0)
+	if err != nil {
+		t.Fatalf("ToolOutputSchema failed: %v", err)
+	}
+	if string(schema) != `{"type":"object"}` {
+		t.Errorf("expected schema from second page, got %s", schema)
+	}
+
+	for _, name := range []string{"echo", "missing"} {
+		schema, err := c.ToolOutputSchema(context.Background(), name, 0)
+		if err != nil || schema != nil {
+			t.Errorf("ToolOutputSchema(%q) = %s, %v; expected no schema", name, schema, err)
+		}
+	}
+}
+
+func TestClientToolOutputSchemaStopsEarly(t *testing.T) {
+	var cursors []string
+	c := newPagedServer(t, map[string]string{
+		"":   `{"tools":[{"name":"weather","inputSchema":{"type":"object"},"outputSchema":{"type":"object"}}],"nextCursor":"c1"}`,
+		"c1": `{"tools":[{"name":"echo","inputSchema":{"type":"object"}}]}`,
+	}, &cursors)
+
+	schema, err := c.ToolOutputSchema(context.Background(), "weather", 0)
+	if err != nil || string(schema) != `{"type":"object"}` {
+		t.Fatalf("ToolOutputSchema = %s, %v", schema, err)
+	}
+	if len(cursors) != 1 {
+		t.Errorf("expected to stop at the first page, got requests %v", cursors)
+	}
+}
+
+func TestClientToolOutputSchemaMaxPages(t *testing.T) {
+	var cursors []string
+	c := newPagedServer(t, map[string]string{
+		"":   `{"tools":[],"nextCursor":"c1"}`,
+		"c1": `{"tools":[],"nextCursor":"c2"}`,
+		"c2": `{"tools":[{"name":"weather","inputSchema":{"type":"object"}}]}`,
+	}, &cursors)
+
+	if _, err := c.ToolOutputSchema(context.Background(), "weather", 2); !errors.Is(err, ErrMaxPages) {
+		t.Errorf("expected ErrMaxPages, got %v", err)
+	}
+	if len(cursors) != 2 {
+		t.Errorf("expected 2 requests, got %v", cursors)
+	}
+
+	cursors = nil
+	c = newPagedServer(t, map[string]string{
+		"":   `{"tools":[],"nextCursor":"c1"}`,
+		"c1": `{"tools":[],"nextCursor":"c1"}`,
+	}, &cursors)
+	if _, err := c.ToolOutputSchema(context.Background(), "weather", 0); err == nil || !strings.Contains(err.Error(), "cursor loop") {
+		t.Errorf("expected cursor loop error, got %v", err)
+	}
+}
+
+func TestClientReadResource(t *testing.T) {
+	server := newToolsServer(t, nil)
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := c.ReadResource(context.Background(), "file:///notes.txt")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].URI != "file:///notes.txt" || *result.Contents[0].Text != "hello" {
+		t.Errorf("unexpected contents %+v", result.Contents)
+	}
+}
+
+func TestClientToolsNoResponse(t *testing.T) {
+	server := newMCPServer(t, map[string]mcpHandler{
+		anyMethod: func(w http.ResponseWriter, r *http.Request, msg protocol.Message) {
+			w.WriteHeader(http.StatusAccepted)
+		},
+	})
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if _, err := c.ToolOutputSchema(context.Background(), "get_weather", 0); err == nil || !strings.Contains(err.Error(), "no response to tools/list") {
+		t.Errorf("expected missing tools/list response error, got %v", err)
+	}
+	if _, err := c.ReadResource(context.Background(), "file:///notes.txt"); err == nil || !strings.Contains(err.Error(), "no response to resources/read") {
+		t.Errorf("expected missing resources/read response error, got %v", err)
+	}
+}
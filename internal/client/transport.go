@@ -1,9 +1,11 @@
 package client
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,103 +15,289 @@ import (
 	"github.com/bigbag/mcpsnag/internal/protocol"
 )
 
+var ErrTerminationNotSupported = errors.New("server does not support session termination (HTTP 405)")
+
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+func readHTTPError(resp *http.Response) *HTTPError {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	return &HTTPError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+func httpTransport(c *http.Client) *http.Transport {
+	return c.Transport.(*http.Transport)
+}
+
 type Transport struct {
-	endpoint   string
-	httpClient *http.Client
-	headers    map[string]string
+	endpoint      string
+	httpClient    *http.Client
+	headers       map[string]string
+	maxReconnects int
+	maxEventSize  int
+	retry         RetryPolicy
+	tlsLogged     bool
+	logger        func(format string, args ...any)
+	onRequest     func(protocol.Message)
 }
 
 func NewTransport(endpoint string, timeout time.Duration) *Transport {
 	return &Transport{
-		endpoint: endpoint,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		headers: make(map[string]string),
+		endpoint:   endpoint,
+		httpClient: newHTTPClient(timeout),
+		headers:    make(map[string]string),
 	}
 }
 
 func (t *Transport) SetHeader(key, value string) {
+	if value == "" {
+		delete(t.headers, key)
+		return
+	}
 	t.headers[key] = value
 }
 
-func (t *Transport) Post(body []byte) (*http.Response, error) {
-	req, err := http.NewRequest("POST", t.endpoint, bytes.NewReader(body))
+func (t *Transport) SetMaxReconnects(n int) {
+	t.maxReconnects = n
+}
+
+func (t *Transport) SetUnixSocket(socket string) {
+	dialUnix(httpTransport(t.httpClient), socket)
+}
+
+func (t *Transport) SetDialer(d *Dialer) {
+	d.configure(httpTransport(t.httpClient))
+}
+
+func (t *Transport) SetTLSConfig(cfg *tls.Config) {
+	httpTransport(t.httpClient).TLSClientConfig = cfg
+}
+
+func (t *Transport) SetRetryPolicy(policy RetryPolicy) {
+	t.retry = policy
+}
+
+func (t *Transport) SetMaxEventSize(n int) {
+	t.maxEventSize = n
+}
+
+func (t *Transport) SetRequestHandler(handler func(protocol.Message)) {
+	t.onRequest = handler
+}
+
+func (t *Transport) SetLogger(logger func(format string, args ...any)) {
+	t.logger = logger
+}
+
+func (t *Transport) logf(format string, args ...any) {
+	if t.logger != nil {
+		t.logger(format, args...)
+	}
+}
+
+func (t *Transport) Close() error {
+	t.httpClient.CloseIdleConnections()
+	return nil
+}
+
+func (t *Transport) Post(ctx context.Context, body []byte) (*http.Response, error) {
+	return t.postWithRetry(ctx, body, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := t.httpClient.Do(req)
+		if err == nil && resp.TLS != nil && !t.tlsLogged {
+			t.tlsLogged = true
+			logTLSState(t.logf, resp.TLS)
+		}
+		return resp, err
+	})
+}
+
+func (t *Transport) openStream(ctx context.Context, client *http.Client, lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Accept", "text/event-stream")
 
 	for k, v := range t.headers {
 		req.Header.Set(k, v)
 	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
 
-	return t.httpClient.Do(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		return nil, errors.New("server does not offer a GET stream (HTTP 405)")
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readHTTPError(resp)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected Content-Type %q for GET stream", contentType)
+	}
+
+	return resp, nil
 }
 
-type SSEEvent struct {
-	Event string
-	Data  string
-	ID    string
+func (t *Transport) Listen(ctx context.Context, onMessage func(json.RawMessage) error) error {
+	streamClient := *t.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := t.openStream(ctx, &streamClient, "")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	s := newSSEStream(t.maxEventSize, func(event SSEEvent) error {
+		if event.Data == "" || (event.Event != "message" && event.Event != "") {
+			return nil
+		}
+		return dispatchListened([]byte(event.Data), onMessage, t.onRequest)
+	})
+	err = s.read(resp.Body)
+	resp.Body.Close()
+
+	err = t.resume(ctx, &streamClient, s, err, func() bool { return false })
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
 }
 
-func ParseSSEStream(r io.Reader, handler func(event SSEEvent) error) error {
-	scanner := bufio.NewScanner(r)
-	var current SSEEvent
+func (t *Transport) resume(ctx context.Context, client *http.Client, s *sseStream, streamErr error, done func() bool) error {
+	attempt := 0
+	for {
+		if s.handlerErr != nil {
+			return s.handlerErr
+		}
+		if done() {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if s.lastID() == "" || attempt >= t.maxReconnects {
+			return streamErr
+		}
+		attempt++
 
-	for scanner.Scan() {
-		line := scanner.Text()
+		if streamErr != nil {
+			t.logf("* Stream dropped: %v", streamErr)
+		} else {
+			t.logf("* Stream closed by server")
+		}
+		t.logf("* Reconnecting in %s with Last-Event-ID: %s (attempt %d/%d)", s.retry(), s.lastID(), attempt, t.maxReconnects)
 
-		if line == "" {
-			if current.Data != "" {
-				if err := handler(current); err != nil {
-					return err
-				}
-			}
-			current = SSEEvent{}
-			continue
+		select {
+		case <-time.After(s.retry()):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 
-		if strings.HasPrefix(line, ":") {
+		lastID := s.lastID()
+		resp, err := t.openStream(ctx, client, lastID)
+		if err != nil {
+			streamErr = err
 			continue
 		}
+		s.resumed()
+		streamErr = s.read(resp.Body)
+		resp.Body.Close()
 
-		colonIdx := strings.Index(line, ":")
-		if colonIdx == -1 {
-			continue
+		if s.lastID() != lastID {
+			attempt = 0
 		}
+	}
+}
 
-		field := line[:colonIdx]
-		value := strings.TrimPrefix(line[colonIdx+1:], " ")
+func (t *Transport) Terminate() error {
+	req, err := http.NewRequest("DELETE", t.endpoint, nil)
+	if err != nil {
+		return err
+	}
 
-		switch field {
-		case "event":
-			current.Event = value
-		case "data":
-			if current.Data != "" {
-				current.Data += "\n"
-			}
-			current.Data += value
-		case "id":
-			current.ID = value
-		}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
 	}
 
-	if current.Data != "" {
-		if err := handler(current); err != nil {
-			return err
-		}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	return scanner.Err()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return ErrTerminationNotSupported
+	default:
+		return readHTTPError(resp)
+	}
 }
 
-func (t *Transport) PostAndReadResponse(body []byte, stream bool, onEvent func(protocol.Response) error) (*protocol.Response, string, error) {
-	resp, err := t.Post(body)
+func (t *Transport) PostAndReadResponse(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) (*protocol.Response, string, error) {
+	m := newSingleResponse(body)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	if err != nil {
+		return nil, sessionID, err
+	}
+	return m.response, sessionID, nil
+}
+
+func (t *Transport) PostBatch(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error) {
+	ids, err := parseBatchIDs(body)
 	if err != nil {
 		return nil, "", err
 	}
+	m := newBatchResponses(ids)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	if err != nil {
+		return nil, sessionID, err
+	}
+	responses, err := m.result()
+	return responses, sessionID, err
+}
+
+func (t *Transport) exchange(ctx context.Context, body []byte, m responseMatcher, stream bool, onEvent func(protocol.Message) error) (string, error) {
+	resp, err := t.Post(ctx, body)
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
 	sessionID := resp.Header.Get(protocol.SessionHeader)
@@ -117,44 +305,39 @@ func (t *Transport) PostAndReadResponse(body []byte, stream bool, onEvent func(p
 	contentType := resp.Header.Get("Content-Type")
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, sessionID, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(bodyBytes))
+		return sessionID, readHTTPError(resp)
 	}
 
 	if resp.StatusCode == http.StatusAccepted {
-		return nil, sessionID, nil
+		return sessionID, nil
 	}
 
 	if strings.HasPrefix(contentType, "text/event-stream") {
-		var lastResponse *protocol.Response
-		err := ParseSSEStream(resp.Body, func(event SSEEvent) error {
-			if event.Event == "message" || event.Event == "" {
-				var jsonResp protocol.Response
-				if err := json.Unmarshal([]byte(event.Data), &jsonResp); err != nil {
-					return err
-				}
-				lastResponse = &jsonResp
-				if stream && onEvent != nil {
-					return onEvent(jsonResp)
-				}
+		s := newSSEStream(t.maxEventSize, func(event SSEEvent) error {
+			if m.done() || event.Data == "" || (event.Event != "message" && event.Event != "") {
+				return nil
 			}
-			return nil
+			return deliver([]byte(event.Data), m, stream, onEvent, t.onRequest)
 		})
-		if err != nil {
-			return nil, sessionID, err
-		}
-		return lastResponse, sessionID, nil
+		err := s.read(resp.Body)
+		return sessionID, t.resume(ctx, t.httpClient, s, err, m.done)
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, sessionID, err
+		return sessionID, err
 	}
 
-	var jsonResp protocol.Response
-	if err := json.Unmarshal(bodyBytes, &jsonResp); err != nil {
-		return nil, sessionID, fmt.Errorf("invalid JSON response: %w", err)
+	msgs, err := decodeMessages(bodyBytes)
+	if err != nil {
+		return sessionID, fmt.Errorf("invalid JSON response: %w", err)
 	}
-
-	return &jsonResp, sessionID, nil
+	for i := range msgs {
+		if !m.accept(&msgs[i]) {
+			if err := route(msgs[i], stream, onEvent, t.onRequest); err != nil {
+				return sessionID, err
+			}
+		}
+	}
+	return sessionID, nil
 }
@@ -1,135 +1,514 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
 )
 
-func TestParseSSEStream(t *testing.T) {
-	input := `event: message
-data: {"jsonrpc":"2.0","id":1,"result":{"tools":[]}}
+func TestTransportListen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if r.Header.Get(protocol.SessionHeader) != "sess-1" {
+			t.Errorf("expected session header, got %q", r.Header.Get(protocol.SessionHeader))
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected SSE Accept header, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/resources/updated\",\"params\":{\"uri\":\"file:///a\"}}\n\n")
+	}))
+	defer server.Close()
 
-event: message
-data: {"jsonrpc":"2.0","id":2,"result":{"done":true}}
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetHeader(protocol.SessionHeader, "sess-1")
 
-`
-	var events []SSEEvent
-	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
-		events = append(events, event)
+	var methods []string
+	err := tr.Listen(context.Background(), func(msg json.RawMessage) error {
+		var notif protocol.Notification
+		if err := json.Unmarshal(msg, &notif); err != nil {
+			return err
+		}
+		methods = append(methods, notif.Method)
 		return nil
 	})
-
 	if err != nil {
-		t.Fatalf("ParseSSEStream failed: %v", err)
+		t.Fatalf("Listen failed: %v", err)
 	}
 
-	if len(events) != 2 {
-		t.Fatalf("expected 2 events, got %d", len(events))
+	expected := []string{"notifications/tools/list_changed", "notifications/resources/updated"}
+	if strings.Join(methods, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, methods)
 	}
+}
 
-	if events[0].Event != "message" {
-		t.Errorf("expected event 'message', got %q", events[0].Event)
+func TestTransportListenMethodNotAllowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	tr := NewTransport(server.URL, time.Second)
+	err := tr.Listen(context.Background(), func(json.RawMessage) error { return nil })
+	if err == nil {
+		t.Fatal("expected error for 405")
 	}
-	if !strings.Contains(events[0].Data, `"tools":[]`) {
-		t.Errorf("unexpected data: %s", events[0].Data)
+	if !strings.Contains(err.Error(), "405") {
+		t.Errorf("expected 405 in error, got %v", err)
 	}
 }
 
-func TestParseSSEStreamMultilineData(t *testing.T) {
-	input := `event: message
-data: line1
-data: line2
+func TestTransportListenCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	tr := NewTransport(server.URL, time.Second)
+	if err := tr.Listen(ctx, func(json.RawMessage) error { return nil }); err != nil {
+		t.Errorf("expected nil error on cancellation, got %v", err)
+	}
+}
+
+func TestTransportTerminate(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantErr  error
+		wantHTTP int
+	}{
+		{name: "no content", status: http.StatusNoContent},
+		{name: "ok", status: http.StatusOK},
+		{name: "not supported", status: http.StatusMethodNotAllowed, wantErr: ErrTerminationNotSupported},
+		{name: "unknown session", status: http.StatusNotFound, wantHTTP: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "DELETE" {
+					t.Errorf("expected DELETE, got %s", r.Method)
+				}
+				if r.Header.Get(protocol.SessionHeader) != "sess-1" {
+					t.Errorf("expected session header, got %q", r.Header.Get(protocol.SessionHeader))
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
 
-`
-	var events []SSEEvent
-	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
-		events = append(events, event)
+			tr := NewTransport(server.URL, time.Second)
+			tr.SetHeader(protocol.SessionHeader, "sess-1")
+			err := tr.Terminate()
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.wantHTTP != 0:
+				var httpErr *HTTPError
+				if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantHTTP {
+					t.Errorf("expected HTTP %d error, got %v", tt.wantHTTP, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientTerminateClearsSession(t *testing.T) {
+	var deleted bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleted = r.Method == "DELETE"
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, SessionID: "sess-1", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := c.Terminate(); err != nil {
+		t.Fatalf("Terminate failed: %v", err)
+	}
+	if !deleted {
+		t.Error("expected DELETE request")
+	}
+	if c.Session().IsValid() {
+		t.Error("expected session to be cleared after termination")
+	}
+	if err := c.Terminate(); err == nil {
+		t.Error("expected error terminating without a session")
+	}
+}
+
+func TestTransportPostResumesDroppedStream(t *testing.T) {
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch r.Method {
+		case "POST":
+			fmt.Fprint(w, "retry: 10\n\n")
+			fmt.Fprint(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progress\":1}}\n\n")
+		case "GET":
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progress\":1}}\n\n")
+			fmt.Fprint(w, "id: 2\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"done\":true}}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetMaxReconnects(2)
+
+	var events int
+	resp, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`), true, func(protocol.Message) error {
+		events++
 		return nil
 	})
-
 	if err != nil {
-		t.Fatalf("ParseSSEStream failed: %v", err)
+		t.Fatalf("PostAndReadResponse failed: %v", err)
 	}
 
-	if len(events) != 1 {
-		t.Fatalf("expected 1 event, got %d", len(events))
+	if resp == nil || !strings.Contains(string(resp.Result), `"done":true`) {
+		t.Fatalf("expected resumed result, got %+v", resp)
+	}
+	if events != 1 {
+		t.Errorf("expected replayed notification to be delivered once, got %d", events)
 	}
+	if len(lastEventIDs) != 1 || lastEventIDs[0] != "1" {
+		t.Errorf("expected one resume with Last-Event-ID 1, got %v", lastEventIDs)
+	}
+}
 
-	expected := "line1\nline2"
-	if events[0].Data != expected {
-		t.Errorf("expected data %q, got %q", expected, events[0].Data)
+func TestTransportPostResumeLimit(t *testing.T) {
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if r.Method == "GET" {
+			gets++
+		}
+		fmt.Fprint(w, "retry: 1\nid: 1\ndata: \n\n")
+	}))
+	defer server.Close()
+
+	tr := NewTransport(server.URL, time.Second)
+	tr.SetMaxReconnects(3)
+
+	resp, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`), false, nil)
+	if err != nil {
+		t.Fatalf("PostAndReadResponse failed: %v", err)
+	}
+	if resp != nil {
+		t.Errorf("expected no response, got %+v", resp)
+	}
+	if gets != 3 {
+		t.Errorf("expected 3 reconnect attempts, got %d", gets)
 	}
 }
 
-func TestParseSSEStreamWithComments(t *testing.T) {
-	input := `: this is a comment
-event: message
-data: test
+func TestTransportPostAnswersServerRequests(t *testing.T) {
+	replies := make(chan protocol.Response, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg protocol.Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if msg.IsResponse() {
+			if r.Header.Get(protocol.SessionHeader) != "sess-1" {
+				t.Errorf("expected session header on reply, got %q", r.Header.Get(protocol.SessionHeader))
+			}
+			replies <- msg.Response()
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
 
-`
-	var events []SSEEvent
-	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
-		events = append(events, event)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"srv-1\",\"method\":\"ping\"}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"srv-2\",\"method\":\"sampling/createMessage\",\"params\":{}}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\",\"params\":{\"level\":\"info\"}}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":99,\"result\":{\"other\":true}}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":5,\"result\":{\"mine\":true}}\n\n")
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, SessionID: "sess-1", Timeout: time.Second, Stream: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	c.requestID.Store(4)
+
+	var methods []string
+	resp, err := c.Request(context.Background(), "tools/call", nil, func(msg protocol.Message) error {
+		methods = append(methods, msg.Method)
 		return nil
 	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if !strings.Contains(string(resp.Result), `"mine":true`) {
+		t.Errorf("expected response matching request ID, got %s", resp.Result)
+	}
+	if len(methods) != 2 || methods[0] != "notifications/message" {
+		t.Errorf("expected notification and unrelated response to be streamed, got %v", methods)
+	}
+
+	close(replies)
+	var got []protocol.Response
+	for r := range replies {
+		got = append(got, r)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 replies, got %d", len(got))
+	}
+	if got[0].ID != "srv-1" || string(got[0].Result) != "{}" {
+		t.Errorf("expected empty ping result for srv-1, got %+v", got[0])
+	}
+	if got[1].ID != "srv-2" || got[1].Error == nil || got[1].Error.Code != protocol.CodeMethodNotFound {
+		t.Errorf("expected method not found for srv-2, got %+v", got[1])
+	}
+}
 
+func TestClientCustomHandler(t *testing.T) {
+	replies := make(chan protocol.Response, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg protocol.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.IsResponse() {
+			replies <- msg.Response()
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":7,\"method\":\"elicitation/create\",\"params\":{\"message\":\"name?\"}}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}}\n\n")
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
 	if err != nil {
-		t.Fatalf("ParseSSEStream failed: %v", err)
+		t.Fatalf("New failed: %v", err)
 	}
+	c.Handle("elicitation/create", func(params json.RawMessage) (any, error) {
+		return nil, &protocol.Error{Code: protocol.CodeInvalidParams, Message: "declined"}
+	})
 
-	if len(events) != 1 {
-		t.Fatalf("expected 1 event, got %d", len(events))
+	if _, err := c.Request(context.Background(), "tools/call", nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
 	}
 
-	if events[0].Data != "test" {
-		t.Errorf("expected data 'test', got %q", events[0].Data)
+	reply := <-replies
+	if reply.Error == nil || reply.Error.Code != protocol.CodeInvalidParams || reply.Error.Message != "declined" {
+		t.Errorf("expected handler error to be returned, got %+v", reply.Error)
 	}
 }
 
-func TestParseSSEStreamWithID(t *testing.T) {
-	input := `event: message
-id: 123
-data: test
+func TestClientRequestWithProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any `json:"id"`
+			Params struct {
+				Meta struct {
+					ProgressToken any `json:"progressToken"`
+				} `json:"_meta"`
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Params.Name != "slow" {
+			t.Errorf("expected original params to be kept, got %q", req.Params.Name)
+		}
+		if !protocol.SameID(req.Params.Meta.ProgressToken, req.ID) {
+			t.Errorf("expected progress token %v, got %v", req.ID, req.Params.Meta.ProgressToken)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progressToken\":%v,\"progress\":1,\"total\":2}}\n\n", req.ID)
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progressToken\":\"other\",\"progress\":9}}\n\n")
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{\"progressToken\":%v,\"progress\":2,\"total\":2,\"message\":\"done\"}}\n\n", req.ID)
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":{}}\n\n", req.ID)
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
 
-`
-	var events []SSEEvent
-	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
-		events = append(events, event)
+	var updates []protocol.ProgressParams
+	var events int
+	_, err = c.Request(context.Background(), "tools/call", json.RawMessage(`{"name":"slow"}`), func(protocol.Message) error {
+		events++
 		return nil
-	})
+	}, WithProgress(func(p protocol.ProgressParams) {
+		updates = append(updates, p)
+	}))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if len(updates) != 2 || updates[1].Message != "done" {
+		t.Errorf("expected 2 progress updates, got %+v", updates)
+	}
+	if events != 0 {
+		t.Errorf("expected no streamed events without --stream, got %d", events)
+	}
+}
 
+func TestClientRequestCancellation(t *testing.T) {
+	cancelled := make(chan protocol.CancelledParams, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg protocol.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Method == "notifications/cancelled" {
+			var params protocol.CancelledParams
+			json.Unmarshal(msg.Params, &params)
+			cancelled <- params
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c, err := New(Options{Endpoint: server.URL, Timeout: 5 * time.Second})
 	if err != nil {
-		t.Fatalf("ParseSSEStream failed: %v", err)
+		t.Fatalf("New failed: %v", err)
 	}
 
-	if len(events) != 1 {
-		t.Fatalf("expected 1 event, got %d", len(events))
+	cause := errors.New("test deadline")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, cause)
+	defer cancel()
+
+	_, err = c.Request(ctx, "tools/call", nil, nil)
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected cancellation cause in error, got %v", err)
 	}
 
-	if events[0].ID != "123" {
-		t.Errorf("expected ID '123', got %q", events[0].ID)
+	select {
+	case params := <-cancelled:
+		if !protocol.SameID(params.RequestID, 1) {
+			t.Errorf("expected requestId 1, got %v", params.RequestID)
+		}
+		if params.Reason != "test deadline" {
+			t.Errorf("expected reason %q, got %q", "test deadline", params.Reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected notifications/cancelled to be sent")
 	}
 }
 
-func TestParseSSEStreamNoEventType(t *testing.T) {
-	input := `data: {"result":"ok"}
+func TestTransportPostBatch(t *testing.T) {
+	body := []byte(`[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"method":"tools/call"}]`)
 
-`
-	var events []SSEEvent
-	err := ParseSSEStream(strings.NewReader(input), func(event SSEEvent) error {
-		events = append(events, event)
-		return nil
-	})
+	tests := map[string]func(w http.ResponseWriter){
+		"json": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"Missing tool name"}},{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}]`)
+		},
+		"sse": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/message\",\"params\":{}}\n\n")
+			fmt.Fprint(w, "data: [{\"jsonrpc\":\"2.0\",\"id\":2,\"error\":{\"code\":-32602,\"message\":\"Missing tool name\"}}]\n\n")
+			fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"tools\":[]}}\n\n")
+		},
+	}
+	for name, respond := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				respond(w)
+			}))
+			defer server.Close()
+
+			tr := NewTransport(server.URL, time.Second)
+			var events int
+			responses, _, err := tr.PostBatch(context.Background(), body, true, func(protocol.Message) error {
+				events++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("PostBatch failed: %v", err)
+			}
+			if len(responses) != 2 {
+				t.Fatalf("expected 2 responses, got %d", len(responses))
+			}
+			if responses[0] == nil || string(responses[0].Result) != `{"tools":[]}` {
+				t.Errorf("expected tools/list result first, got %+v", responses[0])
+			}
+			if responses[1] == nil || responses[1].Error == nil || responses[1].Error.Code != protocol.CodeInvalidParams {
+				t.Errorf("expected per-item error second, got %+v", responses[1])
+			}
+			if name == "sse" && events != 1 {
+				t.Errorf("expected 1 streamed notification, got %d", events)
+			}
+		})
+	}
+}
+
+func TestTransportPostBatchRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batching is not supported"}}`)
+	}))
+	defer server.Close()
+
+	tr := NewTransport(server.URL, time.Second)
+	_, _, err := tr.PostBatch(context.Background(), []byte(`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`), false, nil)
+
+	var rpcErr *protocol.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != protocol.CodeInvalidRequest {
+		t.Errorf("expected batch rejection error, got %v", err)
+	}
+}
+
+func TestClientBatchMissingResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []protocol.Request
+		json.NewDecoder(r.Body).Decode(&reqs)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"jsonrpc":"2.0","id":%v,"result":{}}]`, reqs[0].ID)
+	}))
+	defer server.Close()
 
+	c, err := New(Options{Endpoint: server.URL, Timeout: time.Second})
 	if err != nil {
-		t.Fatalf("ParseSSEStream failed: %v", err)
+		t.Fatalf("New failed: %v", err)
 	}
+	defer c.Close()
 
-	if len(events) != 1 {
-		t.Fatalf("expected 1 event, got %d", len(events))
+	results, err := c.Batch(context.Background(), []protocol.UserRequest{{Method: "ping"}, {Method: "tools/list"}}, nil)
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Response == nil || results[1].Response != nil {
+		t.Errorf("expected only the first request to be answered, got %+v", results)
+	}
+	if results[1].Method != "tools/list" || results[1].ID != int64(2) {
+		t.Errorf("unexpected second result %+v", results[1])
 	}
 
-	if events[0].Event != "" {
-		t.Errorf("expected empty event type, got %q", events[0].Event)
+	if _, err := c.Batch(context.Background(), []protocol.UserRequest{{Method: ""}}, nil); err == nil {
+		t.Error("expected error for batch item without method")
 	}
 }
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const unixScheme = "unix://"
+
+func ParseUnixEndpoint(endpoint string) (socket, httpEndpoint string, err error) {
+	rest, ok := strings.CutPrefix(endpoint, unixScheme)
+	if !ok {
+		return "", "", errors.New("not a unix:// endpoint")
+	}
+
+	head, _, _ := strings.Cut(rest, "?")
+	sep := strings.LastIndex(head, ":/")
+	if sep < 0 {
+		if sep = strings.LastIndex(head, ":"); sep >= 0 {
+			if _, err := os.Stat(head); err == nil {
+				sep = -1
+			} else if strings.ContainsAny(head[sep+1:], "./") {
+				return "", "", fmt.Errorf("ambiguous unix:// endpoint %q: add the HTTP path as :/path or use --unix-socket", endpoint)
+			}
+		}
+	}
+	socket, path := rest, ""
+	if sep >= 0 {
+		socket, path = rest[:sep], rest[sep+1:]
+	}
+	if socket == "" {
+		return "", "", errors.New("unix:// endpoint must include a socket path")
+	}
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return socket, "http://localhost" + path, nil
+}
+
+func dialUnix(tr *http.Transport, socket string) {
+	tr.Proxy = nil
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func TestParseUnixEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		socket   string
+		http     string
+		wantErr  bool
+	}{
+		{"unix:///run/mcp.sock:/mcp", "/run/mcp.sock", "http://localhost/mcp", false},
+		{"unix:///run/mcp.sock:/mcp?x=1", "/run/mcp.sock", "http://localhost/mcp?x=1", false},
+		{"unix:///run/mcp.sock", "/run/mcp.sock", "http://localhost/", false},
+		{"unix://./mcp.sock:mcp", "./mcp.sock", "http://localhost/mcp", false},
+		{"unix:///run/a:b.sock:/mcp", "/run/a:b.sock", "http://localhost/mcp", false},
+		{"unix://./a:b.sock:mcp", "./a:b.sock", "http://localhost/mcp", false},
+		{"unix:///run/mcp.sock:/mcp?next=http://x", "/run/mcp.sock", "http://localhost/mcp?next=http://x", false},
+		{"unix:///tmp/a:b.sock", "", "", true},
+		{"unix:///tmp/a:b.sock:/", "/tmp/a:b.sock", "http://localhost/", false},
+		{"unix://", "", "", true},
+		{"http://localhost/mcp", "", "", true},
+	}
+
+	for _, tt := range tests {
+		socket, endpoint, err := ParseUnixEndpoint(tt.endpoint)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.endpoint, err)
+			continue
+		}
+		if socket != tt.socket || endpoint != tt.http {
+			t.Errorf("%s: got (%q, %q), expected (%q, %q)", tt.endpoint, socket, endpoint, tt.socket, tt.http)
+		}
+	}
+
+	existing := filepath.Join(t.TempDir(), "a:b.sock")
+	if err := os.WriteFile(existing, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	socket, endpoint, err := ParseUnixEndpoint("unix://" + existing)
+	if err != nil || socket != existing || endpoint != "http://localhost/" {
+		t.Errorf("expected existing socket %s to be used as is, got (%q, %q, %v)", existing, socket, endpoint, err)
+	}
+}
+
+func TestClientOverUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mcpsnag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "mcp.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	var paths []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		var req protocol.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set(protocol.SessionHeader, "unix-session")
+		w.Header().Set("Content-Type", "text/event-stream")
+		if req.Method == "initialize" {
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":{\"protocolVersion\":\"2025-03-26\",\"capabilities\":{},\"serverInfo\":{\"name\":\"sidecar\",\"version\":\"1.0\"}}}\n\n", req.ID)
+			return
+		}
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":{\"tools\":[]}}\n\n", req.ID)
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	var logs []string
+	c, err := New(Options{
+		Endpoint: "unix://" + socket + ":/mcp",
+		Timeout:  5 * time.Second,
+		Logger: func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if c.Session().ID != "unix-session" {
+		t.Errorf("expected session %q, got %q", "unix-session", c.Session().ID)
+	}
+
+	resp, err := c.Request(context.Background(), "tools/list", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Result), `"tools":[]`) {
+		t.Errorf("unexpected result: %s", resp.Result)
+	}
+
+	for _, p := range paths {
+		if p != "POST /mcp" {
+			t.Errorf("expected POST /mcp, got %q", p)
+		}
+	}
+	if len(logs) == 0 || !strings.Contains(logs[0], socket) {
+		t.Errorf("expected verbose log to mention the socket, got %v", logs)
+	}
+}
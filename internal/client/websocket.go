@@ -0,0 +1,454 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsAcceptGUID   = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsSubprotocol  = "mcp"
+	wsCloseNormal  = 1000
+	wsCloseTimeout = time.Second
+)
+
+func IsWebSocketURL(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://")
+}
+
+type WebSocketTransport struct {
+	endpoint       string
+	headers        map[string]string
+	timeout        time.Duration
+	maxMessageSize int
+	unixSocket     string
+	tlsConfig      *tls.Config
+	dialer         *Dialer
+	logger         func(format string, args ...any)
+
+	conn      net.Conn
+	writeMu   sync.Mutex
+	messages  chan []byte
+	readErr   error
+	done      chan struct{}
+	closeOnce sync.Once
+	onRequest func(protocol.Message)
+}
+
+func NewWebSocketTransport(endpoint string, timeout time.Duration) *WebSocketTransport {
+	return &WebSocketTransport{
+		endpoint:       endpoint,
+		headers:        make(map[string]string),
+		timeout:        timeout,
+		maxMessageSize: DefaultMaxEventSize,
+		dialer:         &Dialer{net: net.Dialer{Timeout: timeout}},
+		done:           make(chan struct{}),
+	}
+}
+
+func (t *WebSocketTransport) SetHeader(key, value string) {
+	if value == "" {
+		delete(t.headers, key)
+		return
+	}
+	t.headers[key] = value
+}
+
+func (t *WebSocketTransport) SetRequestHandler(handler func(protocol.Message)) {
+	t.onRequest = handler
+}
+
+func (t *WebSocketTransport) SetMaxMessageSize(n int) {
+	if n > 0 {
+		t.maxMessageSize = n
+	}
+}
+
+func (t *WebSocketTransport) SetUnixSocket(socket string) {
+	t.unixSocket = socket
+}
+
+func (t *WebSocketTransport) SetTLSConfig(cfg *tls.Config) {
+	t.tlsConfig = cfg
+}
+
+func (t *WebSocketTransport) SetDialer(d *Dialer) {
+	t.dialer = d
+}
+
+func (t *WebSocketTransport) SetLogger(logger func(format string, args ...any)) {
+	t.logger = logger
+}
+
+func (t *WebSocketTransport) logf(format string, args ...any) {
+	if t.logger != nil {
+		t.logger(format, args...)
+	}
+}
+
+func (t *WebSocketTransport) connect(ctx context.Context) error {
+	if t.conn != nil {
+		return nil
+	}
+
+	u, err := url.Parse(t.endpoint)
+	if err != nil {
+		return err
+	}
+	conn, err := t.dial(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme == "wss" {
+		cfg := &tls.Config{}
+		if t.tlsConfig != nil {
+			cfg = t.tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return err
+		}
+		state := tlsConn.ConnectionState()
+		logTLSState(t.logf, &state)
+		conn = tlsConn
+	}
+
+	br, err := t.handshake(conn, u)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	t.conn = conn
+	t.messages = make(chan []byte, 16)
+	go t.readLoop(br)
+	return nil
+}
+
+func (t *WebSocketTransport) dial(ctx context.Context, u *url.URL) (net.Conn, error) {
+	if t.unixSocket != "" {
+		return t.dialer.net.DialContext(ctx, "unix", t.unixSocket)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	proxyURL, err := t.dialer.Proxy(&http.Request{URL: wsHTTPURL(u)})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		return t.dialer.dialVia(ctx, proxyURL, host)
+	}
+	return t.dialer.DialContext(ctx, "tcp", host)
+}
+
+func wsHTTPURL(u *url.URL) *url.URL {
+	httpURL := *u
+	if u.Scheme == "wss" {
+		httpURL.Scheme = "https"
+	} else {
+		httpURL.Scheme = "http"
+	}
+	return &httpURL
+}
+
+func (t *WebSocketTransport) handshake(conn net.Conn, u *url.URL) (*bufio.Reader, error) {
+	if t.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(t.timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequest("GET", wsHTTPURL(u).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Protocol", wsSubprotocol)
+
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		return nil, readHTTPError(resp)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return nil, fmt.Errorf("unexpected Upgrade header %q in WebSocket handshake", resp.Header.Get("Upgrade"))
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		return nil, errors.New("invalid Sec-WebSocket-Accept in WebSocket handshake")
+	}
+
+	if proto := resp.Header.Get("Sec-WebSocket-Protocol"); proto != "" {
+		t.logf("* WebSocket connected to %s (subprotocol %s)", t.endpoint, proto)
+	} else {
+		t.logf("* WebSocket connected to %s", t.endpoint)
+	}
+	return br, nil
+}
+
+func wsAcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (t *WebSocketTransport) readLoop(r io.Reader) {
+	defer close(t.messages)
+
+	var message []byte
+	for {
+		opcode, fin, payload, err := readWSFrame(r, t.maxMessageSize)
+		if err != nil {
+			if err != io.EOF && !t.isClosed() {
+				t.readErr = err
+			}
+			return
+		}
+
+		switch opcode {
+		case wsOpPing:
+			t.writeFrame(wsOpPong, payload)
+		case wsOpClose:
+			t.logf("* WebSocket closed by server: %s", wsCloseReason(payload))
+			t.writeFrame(wsOpClose, payload[:min(len(payload), 2)])
+			return
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			message = append(message, payload...)
+			if len(message) > t.maxMessageSize {
+				t.readErr = fmt.Errorf("WebSocket message exceeds maximum size (%d bytes)", t.maxMessageSize)
+				return
+			}
+			if !fin {
+				continue
+			}
+			select {
+			case t.messages <- message:
+			case <-t.done:
+				return
+			}
+			message = nil
+		}
+	}
+}
+
+func wsCloseReason(payload []byte) string {
+	if len(payload) < 2 {
+		return "no status"
+	}
+	code := binary.BigEndian.Uint16(payload)
+	if reason := string(payload[2:]); reason != "" {
+		return fmt.Sprintf("%d %s", code, reason)
+	}
+	return fmt.Sprintf("%d", code)
+}
+
+func (t *WebSocketTransport) isClosed() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *WebSocketTransport) writeFrame(opcode byte, payload []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return writeWSFrame(t.conn, opcode, payload, true)
+}
+
+func (t *WebSocketTransport) closedError() error {
+	if t.readErr != nil {
+		return fmt.Errorf("WebSocket connection failed: %w", t.readErr)
+	}
+	return errors.New("WebSocket closed by server")
+}
+
+func (t *WebSocketTransport) PostAndReadResponse(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) (*protocol.Response, string, error) {
+	m := newSingleResponse(body)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	return m.response, sessionID, err
+}
+
+func (t *WebSocketTransport) PostBatch(ctx context.Context, body []byte, stream bool, onEvent func(protocol.Message) error) ([]*protocol.Response, string, error) {
+	ids, err := parseBatchIDs(body)
+	if err != nil {
+		return nil, "", err
+	}
+	m := newBatchResponses(ids)
+	sessionID, err := t.exchange(ctx, body, m, stream, onEvent)
+	if err != nil {
+		return nil, sessionID, err
+	}
+	responses, err := m.result()
+	return responses, sessionID, err
+}
+
+func (t *WebSocketTransport) exchange(ctx context.Context, body []byte, m responseMatcher, stream bool, onEvent func(protocol.Message) error) (string, error) {
+	if err := t.connect(ctx); err != nil {
+		return "", err
+	}
+	if err := t.writeFrame(wsOpText, body); err != nil {
+		return "", fmt.Errorf("failed to write to server: %w", err)
+	}
+	return "", awaitResponses(ctx, t.messages, t.closedError, m, t.timeout, stream, onEvent, t.onRequest)
+}
+
+func (t *WebSocketTransport) Listen(ctx context.Context, onMessage func(json.RawMessage) error) error {
+	if err := t.connect(ctx); err != nil {
+		return err
+	}
+	return listenMessages(ctx, t.messages, t.closedError, onMessage, t.onRequest)
+}
+
+func (t *WebSocketTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		close(t.done)
+		if t.conn == nil {
+			return
+		}
+		t.conn.SetWriteDeadline(time.Now().Add(wsCloseTimeout))
+		payload := binary.BigEndian.AppendUint16(nil, wsCloseNormal)
+		t.writeFrame(wsOpClose, payload)
+		err = t.conn.Close()
+	})
+	return err
+}
+
+func writeWSFrame(w io.Writer, opcode byte, payload []byte, mask bool) error {
+	frame := make([]byte, 2, 14+len(payload))
+	frame[0] = 0x80 | opcode
+
+	var maskBit byte
+	if mask {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame[1] = maskBit | byte(n)
+	case n <= 0xFFFF:
+		frame[1] = maskBit | 126
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame[1] = maskBit | 127
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if !mask {
+		frame = append(frame, payload...)
+	} else {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		frame = append(frame, key[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^key[i%4])
+		}
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
+
+func readWSFrame(r io.Reader, maxSize int) (opcode byte, fin bool, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, false, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	size := uint64(header[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, false, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, false, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if maxSize > 0 && size > uint64(maxSize) {
+		return 0, false, nil, fmt.Errorf("WebSocket frame exceeds maximum size (%d bytes)", maxSize)
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return 0, false, nil, err
+		}
+	}
+
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, false, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+	return opcode, fin, payload, nil
+}
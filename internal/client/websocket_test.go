@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigbag/mcpsnag/internal/protocol"
+)
+
+func newWebSocketServer(t *testing.T, serve func(rw *bufio.ReadWriter)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			http.Error(w, "upgrade required", http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+		rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n")
+		rw.WriteString("Sec-WebSocket-Protocol: " + r.Header.Get("Sec-WebSocket-Protocol") + "\r\n\r\n")
+		rw.Flush()
+
+		serve(rw)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readClientMessage(t *testing.T, rw *bufio.ReadWriter) protocol.Message {
+	t.Helper()
+	for {
+		opcode, _, payload, err := readWSFrame(rw, 0)
+		if err != nil {
+			t.Errorf("server read failed: %v", err)
+			return protocol.Message{}
+		}
+		if opcode != wsOpText {
+			continue
+		}
+		var msg protocol.Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Errorf("invalid client message %s: %v", payload, err)
+		}
+		return msg
+	}
+}
+
+func writeServerMessage(rw *bufio.ReadWriter, s string) {
+	writeWSFrame(rw, wsOpText, []byte(s), false)
+	rw.Flush()
+}
+
+func TestWebSocketTransportRequest(t *testing.T) {
+	var pingReply protocol.Message
+	server := newWebSocketServer(t, func(rw *bufio.ReadWriter) {
+		msg := readClientMessage(t, rw)
+		writeServerMessage(rw, `{"jsonrpc":"2.0","id":`+string(mustJSON(msg.ID))+`,"result":{"protocolVersion":"2025-03-26","capabilities":{},"serverInfo":{"name":"ws","version":"1.0"}}}`)
+
+		if msg = readClientMessage(t, rw); msg.Method != "notifications/initialized" {
+			t.Errorf("expected initialized notification, got %+v", msg)
+		}
+
+		msg = readClientMessage(t, rw)
+		writeWSFrame(rw, wsOpPing, []byte("hi"), false)
+		writeServerMessage(rw, `{"jsonrpc":"2.0","method":"notifications/message","params":{"data":"working"}}`)
+		writeServerMessage(rw, `{"jsonrpc":"2.0","id":"srv-1","method":"ping"}`)
+
+		for {
+			opcode, _, payload, err := readWSFrame(rw, 0)
+			if err != nil {
+				t.Errorf("server read failed: %v", err)
+				return
+			}
+			if opcode == wsOpText {
+				json.Unmarshal(payload, &pingReply)
+				break
+			}
+		}
+
+		result := `{"jsonrpc":"2.0","id":` + string(mustJSON(msg.ID)) + `,"result":{"tools":[]}}`
+		frame := []byte(result)
+		writeFragmented(rw, frame[:10], frame[10:])
+		readWSFrame(rw, 0)
+	})
+
+	c, err := New(Options{
+		Endpoint: "ws" + strings.TrimPrefix(server.URL, "http"),
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		Timeout:  5 * time.Second,
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	result, err := c.Initialize(context.Background())
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ServerInfo.Name != "ws" {
+		t.Errorf("expected server name %q, got %q", "ws", result.ServerInfo.Name)
+	}
+
+	var events []protocol.Message
+	resp, err := c.Request(context.Background(), "tools/list", nil, func(msg protocol.Message) error {
+		events = append(events, msg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !strings.Contains(string(resp.Result), `"tools":[]`) {
+		t.Errorf("unexpected result: %s", resp.Result)
+	}
+	if len(events) != 1 || events[0].Method != "notifications/message" {
+		t.Errorf("expected one notification, got %+v", events)
+	}
+	if !protocol.SameID(pingReply.ID, "srv-1") || string(pingReply.Result) != "{}" {
+		t.Errorf("expected ping reply, got %+v", pingReply)
+	}
+}
+
+func writeFragmented(rw *bufio.ReadWriter, first, rest []byte) {
+	var buf bytes.Buffer
+	writeWSFrame(&buf, wsOpText, first, false)
+	frame := buf.Bytes()
+	frame[0] &^= 0x80
+	rw.Write(frame)
+	writeWSFrame(rw, wsOpContinuation, rest, false)
+	rw.Flush()
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func TestWebSocketTransportHandshakeError(t *testing.T) {
+	server := newWebSocketServer(t, func(*bufio.ReadWriter) {})
+
+	tr := NewWebSocketTransport("ws"+strings.TrimPrefix(server.URL, "http"), time.Second)
+	_, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected HTTP 401 error, got %v", err)
+	}
+}
+
+func TestWebSocketTransportServerClose(t *testing.T) {
+	server := newWebSocketServer(t, func(rw *bufio.ReadWriter) {
+		readClientMessage(t, rw)
+		writeWSFrame(rw, wsOpClose, []byte{0x03, 0xE8}, false)
+		rw.Flush()
+		readWSFrame(rw, 0)
+	})
+
+	tr := NewWebSocketTransport("ws"+strings.TrimPrefix(server.URL, "http"), time.Second)
+	tr.SetHeader("Authorization", "Bearer secret")
+	defer tr.Close()
+
+	_, _, err := tr.PostAndReadResponse(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`), false, nil)
+	if err == nil || !strings.Contains(err.Error(), "closed by server") {
+		t.Errorf("expected closed by server error, got %v", err)
+	}
+}
+
+func TestWebSocketFrameRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+		payload := bytes.Repeat([]byte("a"), size)
+		for _, mask := range []bool{false, true} {
+			var buf bytes.Buffer
+			if err := writeWSFrame(&buf, wsOpText, payload, mask); err != nil {
+				t.Fatal(err)
+			}
+			opcode, fin, got, err := readWSFrame(&buf, 0)
+			if err != nil {
+				t.Fatalf("size %d mask %v: %v", size, mask, err)
+			}
+			if opcode != wsOpText || !fin || !bytes.Equal(got, payload) {
+				t.Errorf("size %d mask %v: round trip mismatch", size, mask)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	writeWSFrame(&buf, wsOpText, make([]byte, 200), true)
+	if _, _, _, err := readWSFrame(&buf, 100); err == nil {
+		t.Error("expected error for frame above max size")
+	}
+}
@@ -0,0 +1,365 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+func Validate(schema, instance json.RawMessage) error {
+	var s, v any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := json.Unmarshal(instance, &v); err != nil {
+		return fmt.Errorf("invalid instance: %w", err)
+	}
+	return (&validator{root: s}).validate(s, v, "$", 0)
+}
+
+const maxRefDepth = 64
+
+type validator struct {
+	root any
+}
+
+func (vd *validator) fail(path, format string, args ...any) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func (vd *validator) validate(schema, v any, path string, depth int) error {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return vd.fail(path, "no value is allowed here")
+		}
+		return nil
+	case map[string]any:
+		return vd.validateObject(s, v, path, depth)
+	default:
+		return fmt.Errorf("invalid schema at %s: expected object or boolean", path)
+	}
+}
+
+func (vd *validator) validateObject(s map[string]any, v any, path string, depth int) error {
+	if ref, ok := s["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return fmt.Errorf("schema $ref nesting too deep at %s", path)
+		}
+		target, err := vd.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if err := vd.validate(target, v, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if t, ok := s["type"]; ok {
+		if err := vd.checkType(t, v, path); err != nil {
+			return err
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+			return vd.fail(path, "value %s is not one of %s", encode(v), encode(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, v) {
+		return vd.fail(path, "value %s does not equal %s", encode(v), encode(c))
+	}
+
+	var err error
+	switch val := v.(type) {
+	case map[string]any:
+		err = vd.checkProperties(s, val, path, depth)
+	case []any:
+		err = vd.checkItems(s, val, path, depth)
+	case string:
+		err = vd.checkString(s, val, path)
+	case float64:
+		err = vd.checkNumber(s, val, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return vd.checkCombinators(s, v, path, depth)
+}
+
+func (vd *validator) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q (only local references are supported)", ref)
+	}
+	node := vd.root
+	if pointer == "" {
+		return node, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			node = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func (vd *validator) checkType(t, v any, path string) error {
+	var allowed []string
+	switch tt := t.(type) {
+	case string:
+		allowed = []string{tt}
+	case []any:
+		for _, name := range tt {
+			if s, ok := name.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	}
+
+	actual := typeOf(v)
+	for _, name := range allowed {
+		if name == actual || (name == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	if actual == "integer" {
+		actual = "number"
+	}
+	return vd.fail(path, "expected %s, got %s", strings.Join(allowed, " or "), actual)
+}
+
+func (vd *validator) checkProperties(s map[string]any, obj map[string]any, path string, depth int) error {
+	if required, ok := s["required"].([]any); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := obj[key]; !present {
+					return vd.fail(path, "missing required property %q", key)
+				}
+			}
+		}
+	}
+	if n, ok := s["minProperties"].(float64); ok && float64(len(obj)) < n {
+		return vd.fail(path, "expected at least %v properties, got %d", n, len(obj))
+	}
+	if n, ok := s["maxProperties"].(float64); ok && float64(len(obj)) > n {
+		return vd.fail(path, "expected at most %v properties, got %d", n, len(obj))
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "." + key
+		if prop, ok := properties[key]; ok {
+			if err := vd.validate(prop, obj[key], childPath, depth); err != nil {
+				return err
+			}
+			continue
+		}
+		additional, ok := s["additionalProperties"]
+		if !ok {
+			continue
+		}
+		if allowed, isBool := additional.(bool); isBool && !allowed {
+			return vd.fail(path, "unexpected property %q", key)
+		}
+		if err := vd.validate(additional, obj[key], childPath, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vd *validator) checkItems(s map[string]any, arr []any, path string, depth int) error {
+	if n, ok := s["minItems"].(float64); ok && float64(len(arr)) < n {
+		return vd.fail(path, "expected at least %v items, got %d", n, len(arr))
+	}
+	if n, ok := s["maxItems"].(float64); ok && float64(len(arr)) > n {
+		return vd.fail(path, "expected at most %v items, got %d", n, len(arr))
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					return vd.fail(path, "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	prefix, _ := s["prefixItems"].([]any)
+	for i, item := range arr {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		var schema any
+		if i < len(prefix) {
+			schema = prefix[i]
+		} else if items, ok := s["items"]; ok {
+			schema = items
+		} else {
+			continue
+		}
+		if err := vd.validate(schema, item, itemPath, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vd *validator) checkString(s map[string]any, str, path string) error {
+	length := utf8.RuneCountInString(str)
+	if n, ok := s["minLength"].(float64); ok && float64(length) < n {
+		return vd.fail(path, "expected at least %v characters, got %d", n, length)
+	}
+	if n, ok := s["maxLength"].(float64); ok && float64(length) > n {
+		return vd.fail(path, "expected at most %v characters, got %d", n, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in schema at %s: %w", pattern, path, err)
+		}
+		if !re.MatchString(str) {
+			return vd.fail(path, "%q does not match pattern %q", str, pattern)
+		}
+	}
+	return nil
+}
+
+func (vd *validator) checkNumber(s map[string]any, n float64, path string) error {
+	if lo, ok := s["minimum"].(float64); ok && n < lo {
+		return vd.fail(path, "%v is less than the minimum %v", n, lo)
+	}
+	if hi, ok := s["maximum"].(float64); ok && n > hi {
+		return vd.fail(path, "%v is greater than the maximum %v", n, hi)
+	}
+	if lo, ok := s["exclusiveMinimum"].(float64); ok && n <= lo {
+		return vd.fail(path, "%v is not greater than %v", n, lo)
+	}
+	if hi, ok := s["exclusiveMaximum"].(float64); ok && n >= hi {
+		return vd.fail(path, "%v is not less than %v", n, hi)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		if q := n / m; q != math.Trunc(q) {
+			return vd.fail(path, "%v is not a multiple of %v", n, m)
+		}
+	}
+	return nil
+}
+
+func (vd *validator) checkCombinators(s map[string]any, v any, path string, depth int) error {
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := vd.validate(sub, v, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		n, err := vd.countMatches(anyOf, v, path, depth)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return vd.fail(path, "value does not match any schema in anyOf")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		n, err := vd.countMatches(oneOf, v, path, depth)
+		if err != nil {
+			return err
+		}
+		if n != 1 {
+			return vd.fail(path, "value matches %d schemas in oneOf, expected exactly 1", n)
+		}
+	}
+	if not, ok := s["not"]; ok {
+		n, err := vd.countMatches([]any{not}, v, path, depth)
+		if err != nil {
+			return err
+		}
+		if n == 1 {
+			return vd.fail(path, "value must not match the schema in not")
+		}
+	}
+	return nil
+}
+
+func (vd *validator) countMatches(schemas []any, v any, path string, depth int) (int, error) {
+	n := 0
+	for _, sub := range schemas {
+		err := vd.validate(sub, v, path, depth)
+		var mismatch *ValidationError
+		switch {
+		case err == nil:
+			n++
+		case !errors.As(err, &mismatch):
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func encode(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
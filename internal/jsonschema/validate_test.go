@@ -0,0 +1,117 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const weatherSchema = `{
+	"type": "object",
+	"properties": {
+		"temperature": {"type": "number", "minimum": -100, "maximum": 100},
+		"conditions": {"type": "string", "enum": ["sunny", "cloudy", "rain"]},
+		"humidity": {"type": "integer"},
+		"readings": {"type": "array", "items": {"$ref": "#/$defs/reading"}, "maxItems": 2},
+		"station": {"type": ["string", "null"], "pattern": "^[A-Z]{4}$"}
+	},
+	"required": ["temperature", "conditions"],
+	"additionalProperties": false,
+	"$defs": {
+		"reading": {"type": "object", "properties": {"at": {"type": "string"}}, "required": ["at"]}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance string
+		wantPath string
+		wantMsg  string
+	}{
+		{"valid", `{"temperature":22.5,"conditions":"sunny","humidity":65,"readings":[{"at":"noon"}],"station":"EGLL"}`, "", ""},
+		{"null station", `{"temperature":1,"conditions":"rain","station":null}`, "", ""},
+		{"missing required", `{"temperature":22.5}`, "$", `missing required property "conditions"`},
+		{"wrong type", `{"temperature":"hot","conditions":"sunny"}`, "$.temperature", "expected number, got string"},
+		{"not integer", `{"temperature":1,"conditions":"sunny","humidity":65.5}`, "$.humidity", "expected integer, got number"},
+		{"enum", `{"temperature":1,"conditions":"snow"}`, "$.conditions", "is not one of"},
+		{"maximum", `{"temperature":101,"conditions":"sunny"}`, "$.temperature", "greater than the maximum"},
+		{"additional", `{"temperature":1,"conditions":"sunny","extra":true}`, "$", `unexpected property "extra"`},
+		{"ref", `{"temperature":1,"conditions":"sunny","readings":[{}]}`, "$.readings[0]", `missing required property "at"`},
+		{"max items", `{"temperature":1,"conditions":"sunny","readings":[{"at":"a"},{"at":"b"},{"at":"c"}]}`, "$.readings", "at most 2 items"},
+		{"pattern", `{"temperature":1,"conditions":"sunny","station":"egll"}`, "$.station", "does not match pattern"},
+		{"root type", `[1,2]`, "$", "expected object, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(weatherSchema), []byte(tt.instance))
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Fatalf("expected valid instance, got %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if verr.Path != tt.wantPath || !strings.Contains(verr.Message, tt.wantMsg) {
+				t.Errorf("expected %s: ...%s..., got %v", tt.wantPath, tt.wantMsg, err)
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	schema := `{"oneOf":[{"type":"string"},{"type":"integer","multipleOf":5}],"not":{"const":"forbidden"}}`
+	for instance, valid := range map[string]bool{
+		`"ok"`:        true,
+		`10`:          true,
+		`7`:           false,
+		`true`:        false,
+		`"forbidden"`: false,
+	} {
+		if err := Validate([]byte(schema), []byte(instance)); (err == nil) != valid {
+			t.Errorf("Validate(%s) = %v, expected valid=%v", instance, err, valid)
+		}
+	}
+
+	anyOf := `{"anyOf":[{"minimum":10},{"maximum":0}],"allOf":[{"type":"number"}]}`
+	for instance, valid := range map[string]bool{`15`: true, `-1`: true, `5`: false, `"x"`: false} {
+		if err := Validate([]byte(anyOf), []byte(instance)); (err == nil) != valid {
+			t.Errorf("Validate(%s) = %v, expected valid=%v", instance, err, valid)
+		}
+	}
+}
+
+func TestValidateSchemaErrors(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"$ref":"https://example.com/schema.json"}`,
+		`{"$ref":"#/$defs/missing"}`,
+		`{"$ref":"#"}`,
+		`{"anyOf":[{"type":"number"},{"$ref":"https://example.com/schema.json"}]}`,
+		`{"not":{"$ref":"https://example.com/schema.json"}}`,
+	}
+	for _, schema := range tests {
+		err := Validate([]byte(schema), []byte(`{}`))
+		var verr *ValidationError
+		if err == nil || errors.As(err, &verr) {
+			t.Errorf("expected schema error for %s, got %v", schema, err)
+		}
+	}
+
+	err := Validate([]byte(`{"oneOf":[{"type":"number"},{"pattern":"["}]}`), []byte(`"x"`))
+	var verr *ValidationError
+	if err == nil || errors.As(err, &verr) {
+		t.Errorf("expected invalid pattern to be a schema error, got %v", err)
+	}
+
+	if err := Validate([]byte(`true`), []byte(`{"any":"thing"}`)); err != nil {
+		t.Errorf("expected true schema to accept anything, got %v", err)
+	}
+	if err := Validate([]byte(`false`), []byte(`{}`)); err == nil {
+		t.Error("expected false schema to reject everything")
+	}
+}
@@ -1,18 +1,29 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 )
 
+const progressBarWidth = 30
+
 type Printer struct {
 	out     io.Writer
 	errOut  io.Writer
 	compact bool
 	verbose bool
+
+	errTTY         bool
+	progressActive bool
+
+	logOut   io.Writer
+	logColor bool
 }
 
 func NewPrinter(out, errOut io.Writer, compact, verbose bool) *Printer {
@@ -21,7 +32,25 @@ func NewPrinter(out, errOut io.Writer, compact, verbose bool) *Printer {
 		errOut:  errOut,
 		compact: compact,
 		verbose: verbose,
+		errTTY:  isTerminal(errOut),
+	}
+}
+
+func (p *Printer) SetLogOutput(w io.Writer) {
+	p.logOut = w
+	p.logColor = isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 func (p *Printer) PrintJSON(v any) error {
@@ -99,14 +128,69 @@ func (p *Printer) PrintResponse(resp *http.Response) {
 	fmt.Fprintln(p.out)
 }
 
+func (p *Printer) PrintText(format string, args ...any) {
+	p.EndProgress()
+	fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+func (p *Printer) PrintEvent(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p.EndProgress()
+	fmt.Fprintln(p.errOut, string(data))
+	return nil
+}
+
+func (p *Printer) PrintProgress(token any, progress float64, total *float64, message string) {
+	if !p.errTTY {
+		update := map[string]any{"progressToken": token, "progress": progress}
+		if total != nil {
+			update["total"] = *total
+		}
+		if message != "" {
+			update["message"] = message
+		}
+		data, _ := json.Marshal(update)
+		fmt.Fprintln(p.errOut, string(data))
+		return
+	}
+
+	var line string
+	if total != nil && *total > 0 {
+		ratio := min(max(progress / *total, 0), 1)
+		filled := int(ratio * progressBarWidth)
+		line = fmt.Sprintf("[%s%s] %3.0f%% %g/%g",
+			strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled), ratio*100, progress, *total)
+	} else {
+		line = fmt.Sprintf("progress: %g", progress)
+	}
+	if message != "" {
+		line += " " + message
+	}
+
+	fmt.Fprintf(p.errOut, "\r\033[K%s", line)
+	p.progressActive = true
+}
+
+func (p *Printer) EndProgress() {
+	if p.progressActive {
+		fmt.Fprintln(p.errOut)
+		p.progressActive = false
+	}
+}
+
 func (p *Printer) PrintVerbose(format string, args ...any) {
 	if !p.verbose {
 		return
 	}
+	p.EndProgress()
 	fmt.Fprintf(p.errOut, format+"\n", args...)
 }
 
 func (p *Printer) PrintError(err error) {
+	p.EndProgress()
 	fmt.Fprintf(p.errOut, "error: %v\n", err)
 }
 
@@ -114,3 +198,46 @@ func (p *Printer) PrintSessionInfo(sessionID string) {
 	data := map[string]string{"sessionId": sessionID}
 	p.PrintJSON(data)
 }
+
+var logLevelColors = map[string]string{
+	"debug":     "\033[90m",
+	"info":      "\033[36m",
+	"notice":    "\033[34m",
+	"warning":   "\033[33m",
+	"error":     "\033[31m",
+	"critical":  "\033[1;31m",
+	"alert":     "\033[1;31m",
+	"emergency": "\033[1;41;97m",
+}
+
+func (p *Printer) PrintLog(at time.Time, level, logger string, data json.RawMessage) {
+	w, color := p.errOut, p.errTTY
+	if p.logOut != nil {
+		w, color = p.logOut, p.logColor
+	} else {
+		p.EndProgress()
+	}
+
+	label := fmt.Sprintf("%-9s", strings.ToUpper(level))
+	if code, ok := logLevelColors[level]; ok && color {
+		label = code + label + "\033[0m"
+	}
+
+	line := at.Format("2006-01-02T15:04:05.000Z07:00") + " " + label + " "
+	if logger != "" {
+		line += "[" + logger + "] "
+	}
+	fmt.Fprintln(w, line+logData(data))
+}
+
+func logData(data json.RawMessage) string {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) != nil {
+		return string(data)
+	}
+	return buf.String()
+}
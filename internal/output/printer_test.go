@@ -6,6 +6,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPrinterPrintJSON(t *testing.T) {
@@ -109,6 +110,83 @@ func TestPrinterPrintRawJSONInvalid(t *testing.T) {
 	}
 }
 
+func TestPrinterPrintEvent(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	p := NewPrinter(&outBuf, &errBuf, false, false)
+
+	event := map[string]any{"method": "notifications/message", "params": map[string]string{"level": "info"}}
+	if err := p.PrintEvent(event); err != nil {
+		t.Fatalf("PrintEvent failed: %v", err)
+	}
+
+	if outBuf.String() != "" {
+		t.Errorf("expected no output to stdout, got %s", outBuf.String())
+	}
+	expected := `{"method":"notifications/message","params":{"level":"info"}}` + "\n"
+	if errBuf.String() != expected {
+		t.Errorf("expected compact event on stderr %q, got %q", expected, errBuf.String())
+	}
+}
+
+func TestPrinterPrintProgressJSONLines(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	p := NewPrinter(&outBuf, &errBuf, false, false)
+
+	total := 10.0
+	p.PrintProgress(7, 5, &total, "halfway")
+	p.PrintProgress("tok", 3, nil, "")
+	p.EndProgress()
+
+	if outBuf.String() != "" {
+		t.Errorf("expected no output to stdout, got %s", outBuf.String())
+	}
+	expected := `{"message":"halfway","progress":5,"progressToken":7,"total":10}` + "\n" +
+		`{"progress":3,"progressToken":"tok"}` + "\n"
+	if errBuf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, errBuf.String())
+	}
+}
+
+func TestPrinterPrintProgressTTY(t *testing.T) {
+	var errBuf bytes.Buffer
+	p := NewPrinter(&bytes.Buffer{}, &errBuf, false, false)
+	p.errTTY = true
+
+	total := 10.0
+	p.PrintProgress(1, 5, &total, "halfway")
+	p.PrintProgress(1, 10, &total, "")
+	p.PrintProgress(1, 12, nil, "")
+	p.EndProgress()
+
+	output := errBuf.String()
+	if !strings.Contains(output, "\r\033[K[###############...............]  50% 5/10 halfway") {
+		t.Errorf("expected half-filled bar, got %q", output)
+	}
+	if !strings.Contains(output, "100% 10/10") {
+		t.Errorf("expected completed bar, got %q", output)
+	}
+	if !strings.Contains(output, "progress: 12") {
+		t.Errorf("expected plain progress without a total, got %q", output)
+	}
+	if !strings.HasSuffix(output, "\n") {
+		t.Errorf("expected EndProgress to terminate the line, got %q", output)
+	}
+}
+
+func TestPrinterPrintText(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	p := NewPrinter(&outBuf, &errBuf, false, false)
+
+	p.PrintText("reply seq=%d time=%.3f ms", 1, 1.5)
+
+	if outBuf.String() != "reply seq=1 time=1.500 ms\n" {
+		t.Errorf("unexpected output %q", outBuf.String())
+	}
+	if errBuf.String() != "" {
+		t.Errorf("expected no output to stderr, got %q", errBuf.String())
+	}
+}
+
 func TestPrinterPrintVerbose(t *testing.T) {
 	var errBuf bytes.Buffer
 	p := NewPrinter(&bytes.Buffer{}, &errBuf, false, true)
@@ -205,3 +283,49 @@ func TestPrinterPrintRequestNotVerbose(t *testing.T) {
 		t.Errorf("expected no output when not verbose, got %s", output)
 	}
 }
+
+func TestPrinterPrintLog(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	p := NewPrinter(&outBuf, &errBuf, false, false)
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	p.PrintLog(at, "error", "database", json.RawMessage(`{"error": "Connection failed"}`))
+	p.PrintLog(at, "info", "", json.RawMessage(`"server ready"`))
+
+	if outBuf.String() != "" {
+		t.Errorf("expected no output to stdout, got %s", outBuf.String())
+	}
+	expected := "2026-01-02T03:04:05.006Z ERROR     [database] {\"error\":\"Connection failed\"}\n" +
+		"2026-01-02T03:04:05.006Z INFO      server ready\n"
+	if errBuf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, errBuf.String())
+	}
+}
+
+func TestPrinterPrintLogColor(t *testing.T) {
+	var errBuf bytes.Buffer
+	p := NewPrinter(&bytes.Buffer{}, &errBuf, false, false)
+	p.errTTY = true
+
+	p.PrintLog(time.Now(), "warning", "", json.RawMessage(`"disk almost full"`))
+
+	if !strings.Contains(errBuf.String(), "\033[33mWARNING  \033[0m disk almost full") {
+		t.Errorf("expected yellow warning label, got %q", errBuf.String())
+	}
+}
+
+func TestPrinterPrintLogToFile(t *testing.T) {
+	var errBuf, logBuf bytes.Buffer
+	p := NewPrinter(&bytes.Buffer{}, &errBuf, false, false)
+	p.errTTY = true
+	p.SetLogOutput(&logBuf)
+
+	p.PrintLog(time.Now(), "debug", "cache", json.RawMessage(`"miss"`))
+
+	if errBuf.String() != "" {
+		t.Errorf("expected no log output on stderr, got %q", errBuf.String())
+	}
+	if !strings.HasSuffix(logBuf.String(), " DEBUG     [cache] miss\n") {
+		t.Errorf("expected uncolored log line in file, got %q", logBuf.String())
+	}
+}
@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"maps"
+	"slices"
+)
+
+type ClientProfile struct {
+	Capabilities ClientCapabilities `json:"capabilities"`
+	ClientInfo   Implementation     `json:"clientInfo"`
+}
+
+var clientPresets = map[string]func() ClientProfile{
+	"mcpsnag": func() ClientProfile {
+		params := DefaultInitializeParams()
+		return ClientProfile{Capabilities: params.Capabilities, ClientInfo: params.ClientInfo}
+	},
+	"claude-desktop": func() ClientProfile {
+		return ClientProfile{
+			ClientInfo: Implementation{Name: "claude-ai", Version: "0.1.0"},
+		}
+	},
+	"cursor": func() ClientProfile {
+		return ClientProfile{
+			Capabilities: ClientCapabilities{Roots: &RootsCapability{}},
+			ClientInfo:   Implementation{Name: "cursor-vscode", Version: "1.0.0"},
+		}
+	},
+	"vscode": func() ClientProfile {
+		return ClientProfile{
+			Capabilities: ClientCapabilities{
+				Roots:       &RootsCapability{ListChanged: true},
+				Sampling:    &SamplingCapability{},
+				Elicitation: &ElicitationCapability{},
+			},
+			ClientInfo: Implementation{Name: "Visual Studio Code", Version: "1.101.0"},
+		}
+	},
+	"mcp-inspector": func() ClientProfile {
+		return ClientProfile{
+			Capabilities: ClientCapabilities{
+				Roots:       &RootsCapability{ListChanged: true},
+				Sampling:    &SamplingCapability{},
+				Elicitation: &ElicitationCapability{},
+			},
+			ClientInfo: Implementation{Name: "mcp-inspector", Version: "0.16.0"},
+		}
+	},
+}
+
+func ClientPreset(name string) (ClientProfile, bool) {
+	preset, ok := clientPresets[name]
+	if !ok {
+		return ClientProfile{}, false
+	}
+	return preset(), true
+}
+
+func ClientPresetNames() []string {
+	return slices.Sorted(maps.Keys(clientPresets))
+}
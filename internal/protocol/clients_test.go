@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestClientPresetNames(t *testing.T) {
+	names := ClientPresetNames()
+	if !slices.IsSorted(names) {
+		t.Errorf("expected sorted preset names, got %v", names)
+	}
+	for _, name := range []string{"mcpsnag", "claude-desktop", "cursor", "vscode", "mcp-inspector"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected preset %q in %v", name, names)
+		}
+	}
+}
+
+func TestClientPreset(t *testing.T) {
+	profile, ok := ClientPreset("vscode")
+	if !ok {
+		t.Fatal("expected vscode preset")
+	}
+	if profile.ClientInfo.Name != "Visual Studio Code" {
+		t.Errorf("unexpected client name %q", profile.ClientInfo.Name)
+	}
+	if profile.Capabilities.Sampling == nil || profile.Capabilities.Elicitation == nil {
+		t.Error("expected vscode preset to declare sampling and elicitation")
+	}
+
+	profile.Capabilities.Roots.ListChanged = false
+	again, _ := ClientPreset("vscode")
+	if !again.Capabilities.Roots.ListChanged {
+		t.Error("expected each call to return an independent copy")
+	}
+
+	if _, ok := ClientPreset("unknown"); ok {
+		t.Error("expected unknown preset to be rejected")
+	}
+}
+
+func TestClientPresetDefault(t *testing.T) {
+	profile, _ := ClientPreset("mcpsnag")
+	params := DefaultInitializeParams()
+	if !reflect.DeepEqual(profile.Capabilities, params.Capabilities) || profile.ClientInfo != params.ClientInfo {
+		t.Errorf("expected mcpsnag preset to match the defaults, got %+v", profile)
+	}
+}
+
+func TestClientCapabilitiesRoundTrip(t *testing.T) {
+	assertRoundTrip(t, `{
+		"experimental": {"tasks": {"poll": true}},
+		"roots": {"listChanged": true},
+		"sampling": {},
+		"elicitation": {},
+		"futureFeature": {"enabled": true}
+	}`, &ClientCapabilities{})
+}
+
+func TestClientCapabilitiesExtra(t *testing.T) {
+	var caps ClientCapabilities
+	if err := json.Unmarshal([]byte(`{"sampling":{},"futureFeature":{}}`), &caps); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if caps.Sampling == nil || caps.Roots != nil {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if string(caps.Extra["futureFeature"]) != "{}" {
+		t.Errorf("expected futureFeature in Extra, got %v", caps.Extra)
+	}
+}
@@ -0,0 +1,46 @@
+package protocol
+
+import "strings"
+
+const (
+	RefTypePrompt   = "ref/prompt"
+	RefTypeResource = "ref/resource"
+)
+
+type CompleteReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+func ParseCompleteReference(ref string) CompleteReference {
+	if strings.Contains(ref, "://") {
+		return CompleteReference{Type: RefTypeResource, URI: ref}
+	}
+	return CompleteReference{Type: RefTypePrompt, Name: ref}
+}
+
+type CompleteArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CompleteContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type CompleteParams struct {
+	Ref      CompleteReference `json:"ref"`
+	Argument CompleteArgument  `json:"argument"`
+	Context  *CompleteContext  `json:"context,omitempty"`
+}
+
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   *int     `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore"`
+}
+
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
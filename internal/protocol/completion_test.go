@@ -0,0 +1,46 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCompleteReference(t *testing.T) {
+	tests := map[string]CompleteReference{
+		"code_review":        {Type: RefTypePrompt, Name: "code_review"},
+		"file:///{path}":     {Type: RefTypeResource, URI: "file:///{path}"},
+		"github://repos/{o}": {Type: RefTypeResource, URI: "github://repos/{o}"},
+	}
+	for ref, want := range tests {
+		if got := ParseCompleteReference(ref); got != want {
+			t.Errorf("ParseCompleteReference(%q) = %+v, expected %+v", ref, got, want)
+		}
+	}
+}
+
+func TestCompleteParamsJSON(t *testing.T) {
+	params := CompleteParams{
+		Ref:      ParseCompleteReference("code_review"),
+		Argument: CompleteArgument{Name: "language", Value: "py"},
+		Context:  &CompleteContext{Arguments: map[string]string{"framework": "django"}},
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{"ref":{"type":"ref/prompt","name":"code_review"},"argument":{"name":"language","value":"py"},"context":{"arguments":{"framework":"django"}}}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestCompleteResultJSON(t *testing.T) {
+	var result CompleteResult
+	if err := json.Unmarshal([]byte(`{"completion":{"values":["python","pytorch"],"total":10,"hasMore":true}}`), &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	c := result.Completion
+	if len(c.Values) != 2 || c.Total == nil || *c.Total != 10 || !c.HasMore {
+		t.Errorf("unexpected completion %+v", c)
+	}
+}
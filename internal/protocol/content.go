@@ -0,0 +1,227 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResource     = "resource"
+	ContentTypeResourceLink = "resource_link"
+)
+
+type Annotations struct {
+	Audience     []string `json:"audience,omitempty"`
+	Priority     *float64 `json:"priority,omitempty"`
+	LastModified string   `json:"lastModified,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (a *Annotations) UnmarshalJSON(data []byte) error {
+	type plain Annotations
+	return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a Annotations) MarshalJSON() ([]byte, error) {
+	type plain Annotations
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+type ContentBlock interface {
+	ContentType() string
+}
+
+type Content struct {
+	ContentBlock
+}
+
+func (c Content) MarshalJSON() ([]byte, error) {
+	if c.ContentBlock == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(c.ContentBlock)
+}
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return fmt.Errorf("invalid content block: %w", err)
+	}
+
+	var block ContentBlock
+	switch head.Type {
+	case ContentTypeText:
+		block = &TextContent{}
+	case ContentTypeImage:
+		block = &ImageContent{}
+	case ContentTypeAudio:
+		block = &AudioContent{}
+	case ContentTypeResource:
+		block = &EmbeddedResource{}
+	case ContentTypeResourceLink:
+		block = &ResourceLink{}
+	default:
+		block = &UnknownContent{}
+	}
+	if err := json.Unmarshal(data, block); err != nil {
+		return fmt.Errorf("invalid %q content block: %w", head.Type, err)
+	}
+	c.ContentBlock = block
+	return nil
+}
+
+type TextContent struct {
+	Text        string          `json:"text"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *TextContent) ContentType() string { return ContentTypeText }
+
+func (c *TextContent) UnmarshalJSON(data []byte) error {
+	type plain TextContent
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra, "type")
+}
+
+func (c TextContent) MarshalJSON() ([]byte, error) {
+	type plain TextContent
+	return marshalTagged(ContentTypeText, plain(c), c.Extra)
+}
+
+type ImageContent struct {
+	Data        *string         `json:"data,omitempty"`
+	MimeType    *string         `json:"mimeType,omitempty"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *ImageContent) ContentType() string { return ContentTypeImage }
+
+func (c *ImageContent) UnmarshalJSON(data []byte) error {
+	type plain ImageContent
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra, "type")
+}
+
+func (c ImageContent) MarshalJSON() ([]byte, error) {
+	type plain ImageContent
+	return marshalTagged(ContentTypeImage, plain(c), c.Extra)
+}
+
+type AudioContent struct {
+	Data        *string         `json:"data,omitempty"`
+	MimeType    *string         `json:"mimeType,omitempty"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *AudioContent) ContentType() string { return ContentTypeAudio }
+
+func (c *AudioContent) UnmarshalJSON(data []byte) error {
+	type plain AudioContent
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra, "type")
+}
+
+func (c AudioContent) MarshalJSON() ([]byte, error) {
+	type plain AudioContent
+	return marshalTagged(ContentTypeAudio, plain(c), c.Extra)
+}
+
+type EmbeddedResource struct {
+	Resource    ResourceContents `json:"resource"`
+	Annotations *Annotations     `json:"annotations,omitempty"`
+	Meta        json.RawMessage  `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *EmbeddedResource) ContentType() string { return ContentTypeResource }
+
+func (c *EmbeddedResource) UnmarshalJSON(data []byte) error {
+	type plain EmbeddedResource
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra, "type")
+}
+
+func (c EmbeddedResource) MarshalJSON() ([]byte, error) {
+	type plain EmbeddedResource
+	return marshalTagged(ContentTypeResource, plain(c), c.Extra)
+}
+
+type ResourceLink struct {
+	URI         string          `json:"uri"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Size        *int64          `json:"size,omitempty"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *ResourceLink) ContentType() string { return ContentTypeResourceLink }
+
+func (c *ResourceLink) UnmarshalJSON(data []byte) error {
+	type plain ResourceLink
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra, "type")
+}
+
+func (c ResourceLink) MarshalJSON() ([]byte, error) {
+	type plain ResourceLink
+	return marshalTagged(ContentTypeResourceLink, plain(c), c.Extra)
+}
+
+type UnknownContent struct {
+	Type string
+	Raw  json.RawMessage
+}
+
+func (c *UnknownContent) ContentType() string { return c.Type }
+
+func (c *UnknownContent) UnmarshalJSON(data []byte) error {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+	c.Type = head.Type
+	c.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (c UnknownContent) MarshalJSON() ([]byte, error) {
+	return c.Raw, nil
+}
+
+type ResourceContents struct {
+	URI      string          `json:"uri"`
+	MimeType string          `json:"mimeType,omitempty"`
+	Text     *string         `json:"text,omitempty"`
+	Blob     *string         `json:"blob,omitempty"`
+	Meta     json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ResourceContents) UnmarshalJSON(data []byte) error {
+	type plain ResourceContents
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ResourceContents) MarshalJSON() ([]byte, error) {
+	type plain ResourceContents
+	return marshalWithExtra(plain(r), r.Extra)
+}
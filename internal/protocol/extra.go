@@ -0,0 +1,109 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var knownFieldsCache sync.Map
+
+func knownFields(t reflect.Type) map[string]bool {
+	if cached, ok := knownFieldsCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields[name] = true
+	}
+	knownFieldsCache.Store(t, fields)
+	return fields
+}
+
+func unmarshalWithExtra(data []byte, v any, extra *map[string]json.RawMessage, reserved ...string) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	known := knownFields(reflect.TypeOf(v).Elem())
+	*extra = nil
+	for key, value := range all {
+		if known[key] || slices.Contains(reserved, key) {
+			continue
+		}
+		if *extra == nil {
+			*extra = make(map[string]json.RawMessage)
+		}
+		(*extra)[key] = value
+	}
+	return nil
+}
+
+func marshalWithExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	return marshalTagged("", v, extra)
+}
+
+func marshalTagged(typ string, v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	fields := bytes.TrimSpace(data[1 : len(data)-1])
+	n := 0
+	if typ != "" {
+		buf.WriteString(`"type":`)
+		t, _ := json.Marshal(typ)
+		buf.Write(t)
+		n++
+	}
+	if len(fields) > 0 {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(fields)
+		n++
+	}
+
+	known := knownFields(reflect.TypeOf(v))
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if !known[key] && (typ == "" || key != "type") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+		n++
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -4,6 +4,14 @@ import "encoding/json"
 
 const JSONRPCVersion = "2.0"
 
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
 type Request struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      any             `json:"id,omitempty"`
@@ -45,6 +53,59 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && m.ID != nil
+}
+
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && m.ID == nil
+}
+
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && (m.Result != nil || m.Error != nil)
+}
+
+func (m *Message) Response() Response {
+	return Response{
+		JSONRPC: m.JSONRPC,
+		ID:      m.ID,
+		Result:  m.Result,
+		Error:   m.Error,
+	}
+}
+
+func NewResponse(id any, result any) (*Response, error) {
+	if result == nil {
+		result = struct{}{}
+	}
+	r, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Result:  r,
+	}, nil
+}
+
+func NewErrorResponse(id any, code int, message string) *Response {
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error:   &Error{Code: code, Message: message},
+	}
+}
+
 type Notification struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Method  string          `json:"method"`
@@ -71,3 +132,18 @@ type UserRequest struct {
 	Method string          `json:"method"`
 	Params json.RawMessage `json:"params,omitempty"`
 }
+
+func SameID(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ra, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	rb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ra) == string(rb)
+}
@@ -88,3 +88,78 @@ func TestErrorImplementsError(t *testing.T) {
 		t.Errorf("expected %q, got %q", "Invalid Request", e.Error())
 	}
 }
+
+func TestSameID(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     any
+		expected bool
+	}{
+		{name: "int and decoded float", a: int64(1), b: float64(1), expected: true},
+		{name: "equal strings", a: "abc", b: "abc", expected: true},
+		{name: "number and string", a: 1, b: "1", expected: false},
+		{name: "different numbers", a: 1, b: 2, expected: false},
+		{name: "both nil", a: nil, b: nil, expected: true},
+		{name: "one nil", a: 1, b: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameID(tt.a, tt.b); got != tt.expected {
+				t.Errorf("SameID(%v, %v) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMessageClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		request      bool
+		notification bool
+		response     bool
+	}{
+		{name: "request", data: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, request: true},
+		{name: "notification", data: `{"jsonrpc":"2.0","method":"notifications/progress"}`, notification: true},
+		{name: "result", data: `{"jsonrpc":"2.0","id":1,"result":{}}`, response: true},
+		{name: "error", data: `{"jsonrpc":"2.0","id":"a","error":{"code":-32601,"message":"nope"}}`, response: true},
+		{name: "error without id", data: `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse"}}`, response: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.data), &msg); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if msg.IsRequest() != tt.request {
+				t.Errorf("IsRequest() = %v, expected %v", msg.IsRequest(), tt.request)
+			}
+			if msg.IsNotification() != tt.notification {
+				t.Errorf("IsNotification() = %v, expected %v", msg.IsNotification(), tt.notification)
+			}
+			if msg.IsResponse() != tt.response {
+				t.Errorf("IsResponse() = %v, expected %v", msg.IsResponse(), tt.response)
+			}
+		})
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	resp, err := NewResponse(float64(3), nil)
+	if err != nil {
+		t.Fatalf("NewResponse failed: %v", err)
+	}
+	if string(resp.Result) != "{}" {
+		t.Errorf("expected empty object result, got %s", resp.Result)
+	}
+
+	errResp := NewErrorResponse("x", CodeMethodNotFound, "Method not found")
+	if errResp.Error == nil || errResp.Error.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found error, got %+v", errResp.Error)
+	}
+	if errResp.Result != nil {
+		t.Errorf("expected no result, got %s", errResp.Result)
+	}
+}
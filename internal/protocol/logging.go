@@ -0,0 +1,46 @@
+package protocol
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+const (
+	LogLevelDebug     = "debug"
+	LogLevelInfo      = "info"
+	LogLevelNotice    = "notice"
+	LogLevelWarning   = "warning"
+	LogLevelError     = "error"
+	LogLevelCritical  = "critical"
+	LogLevelAlert     = "alert"
+	LogLevelEmergency = "emergency"
+)
+
+var LogLevels = []string{
+	LogLevelDebug,
+	LogLevelInfo,
+	LogLevelNotice,
+	LogLevelWarning,
+	LogLevelError,
+	LogLevelCritical,
+	LogLevelAlert,
+	LogLevelEmergency,
+}
+
+func IsLogLevel(level string) bool {
+	return slices.Contains(LogLevels, level)
+}
+
+func LogSeverity(level string) int {
+	return slices.Index(LogLevels, level)
+}
+
+type SetLevelParams struct {
+	Level string `json:"level"`
+}
+
+type LoggingMessageParams struct {
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIsLogLevel(t *testing.T) {
+	for _, level := range []string{"debug", "warning", "emergency"} {
+		if !IsLogLevel(level) {
+			t.Errorf("expected %q to be a log level", level)
+		}
+	}
+	for _, level := range []string{"", "warn", "DEBUG", "trace"} {
+		if IsLogLevel(level) {
+			t.Errorf("expected %q not to be a log level", level)
+		}
+	}
+}
+
+func TestLogSeverity(t *testing.T) {
+	if LogSeverity(LogLevelDebug) >= LogSeverity(LogLevelInfo) {
+		t.Error("expected debug to be less severe than info")
+	}
+	if LogSeverity(LogLevelError) >= LogSeverity(LogLevelEmergency) {
+		t.Error("expected error to be less severe than emergency")
+	}
+	if LogSeverity("trace") != -1 {
+		t.Errorf("expected -1 for an unknown level, got %d", LogSeverity("trace"))
+	}
+}
+
+func TestLoggingMessageParamsJSON(t *testing.T) {
+	var params LoggingMessageParams
+	data := `{"level":"error","logger":"database","data":{"error":"Connection failed"}}`
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if params.Level != LogLevelError || params.Logger != "database" {
+		t.Errorf("unexpected params: %+v", params)
+	}
+	if string(params.Data) != `{"error":"Connection failed"}` {
+		t.Errorf("unexpected data: %s", params.Data)
+	}
+}
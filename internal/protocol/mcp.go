@@ -1,10 +1,23 @@
 package protocol
 
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
 const (
-	MCPVersion    = "2025-03-26"
-	SessionHeader = "Mcp-Session-Id"
+	MCPVersion            = "2025-03-26"
+	SessionHeader         = "Mcp-Session-Id"
+	ProtocolVersionHeader = "MCP-Protocol-Version"
 )
 
+var SupportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+func IsSupportedVersion(version string) bool {
+	return slices.Contains(SupportedVersions, version)
+}
+
 type InitializeParams struct {
 	ProtocolVersion string             `json:"protocolVersion"`
 	Capabilities    ClientCapabilities `json:"capabilities"`
@@ -12,8 +25,22 @@ type InitializeParams struct {
 }
 
 type ClientCapabilities struct {
-	Roots    *RootsCapability    `json:"roots,omitempty"`
-	Sampling *SamplingCapability `json:"sampling,omitempty"`
+	Experimental map[string]json.RawMessage `json:"experimental,omitempty"`
+	Roots        *RootsCapability           `json:"roots,omitempty"`
+	Sampling     *SamplingCapability        `json:"sampling,omitempty"`
+	Elicitation  *ElicitationCapability     `json:"elicitation,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (c *ClientCapabilities) UnmarshalJSON(data []byte) error {
+	type plain ClientCapabilities
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+func (c ClientCapabilities) MarshalJSON() ([]byte, error) {
+	type plain ClientCapabilities
+	return marshalWithExtra(plain(c), c.Extra)
 }
 
 type RootsCapability struct {
@@ -22,8 +49,11 @@ type RootsCapability struct {
 
 type SamplingCapability struct{}
 
+type ElicitationCapability struct{}
+
 type Implementation struct {
 	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
 }
 
@@ -34,12 +64,15 @@ type InitializeResult struct {
 }
 
 type ServerCapabilities struct {
-	Logging   *LoggingCapability   `json:"logging,omitempty"`
-	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
-	Resources *ResourcesCapability `json:"resources,omitempty"`
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
+	Logging     *LoggingCapability     `json:"logging,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
 }
 
+type CompletionsCapability struct{}
+
 type LoggingCapability struct{}
 
 type PromptsCapability struct {
@@ -67,3 +100,45 @@ func DefaultInitializeParams() InitializeParams {
 		},
 	}
 }
+
+type CancelledParams struct {
+	RequestID any    `json:"requestId"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type ProgressParams struct {
+	ProgressToken any      `json:"progressToken"`
+	Progress      float64  `json:"progress"`
+	Total         *float64 `json:"total,omitempty"`
+	Message       string   `json:"message,omitempty"`
+}
+
+func WithProgressToken(params json.RawMessage, token any) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &fields); err != nil {
+			return nil, fmt.Errorf("params must be an object to attach a progress token: %w", err)
+		}
+	}
+
+	meta := make(map[string]json.RawMessage)
+	if raw, ok := fields["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, fmt.Errorf("invalid _meta: %w", err)
+		}
+	}
+
+	tokenRaw, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	meta["progressToken"] = tokenRaw
+
+	metaRaw, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	fields["_meta"] = metaRaw
+
+	return json.Marshal(fields)
+}
@@ -18,6 +18,22 @@ func TestSessionHeader(t *testing.T) {
 	}
 }
 
+func TestIsSupportedVersion(t *testing.T) {
+	if !IsSupportedVersion(MCPVersion) {
+		t.Errorf("expected default version %q to be supported", MCPVersion)
+	}
+	for _, v := range []string{"2025-06-18", "2024-11-05"} {
+		if !IsSupportedVersion(v) {
+			t.Errorf("expected %q to be supported", v)
+		}
+	}
+	for _, v := range []string{"", "2023-01-01", "latest"} {
+		if IsSupportedVersion(v) {
+			t.Errorf("expected %q to be unsupported", v)
+		}
+	}
+}
+
 func TestDefaultInitializeParams(t *testing.T) {
 	params := DefaultInitializeParams()
 
@@ -134,6 +150,20 @@ func TestServerCapabilitiesOptionalFields(t *testing.T) {
 	if result.Capabilities.Logging != nil {
 		t.Error("Capabilities.Logging should be nil when not provided")
 	}
+
+	if result.Capabilities.Completions != nil {
+		t.Error("Capabilities.Completions should be nil when not provided")
+	}
+}
+
+func TestServerCapabilitiesCompletions(t *testing.T) {
+	var caps ServerCapabilities
+	if err := json.Unmarshal([]byte(`{"completions":{},"prompts":{}}`), &caps); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if caps.Completions == nil {
+		t.Error("Capabilities.Completions should be set when advertised")
+	}
 }
 
 func TestImplementationJSON(t *testing.T) {
@@ -160,3 +190,52 @@ func TestImplementationJSON(t *testing.T) {
 		t.Errorf("Version mismatch: %q vs %q", parsed.Version, impl.Version)
 	}
 }
+
+func TestWithProgressToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		expected string
+	}{
+		{name: "nil params", params: "", expected: `{"_meta":{"progressToken":42}}`},
+		{name: "existing params", params: `{"name":"search"}`, expected: `{"_meta":{"progressToken":42},"name":"search"}`},
+		{name: "existing meta", params: `{"_meta":{"trace":"x"},"name":"a"}`, expected: `{"_meta":{"progressToken":42,"trace":"x"},"name":"a"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithProgressToken(json.RawMessage(tt.params), 42)
+			if err != nil {
+				t.Fatalf("WithProgressToken failed: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithProgressTokenNonObject(t *testing.T) {
+	if _, err := WithProgressToken(json.RawMessage(`[1,2]`), 1); err == nil {
+		t.Error("expected error for array params")
+	}
+}
+
+func TestProgressParamsJSON(t *testing.T) {
+	var p ProgressParams
+	data := `{"progressToken":"abc","progress":5,"total":10,"message":"halfway"}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if p.ProgressToken != "abc" || p.Progress != 5 || p.Total == nil || *p.Total != 10 || p.Message != "halfway" {
+		t.Errorf("unexpected progress params: %+v", p)
+	}
+
+	var noTotal ProgressParams
+	if err := json.Unmarshal([]byte(`{"progressToken":1,"progress":0}`), &noTotal); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if noTotal.Total != nil {
+		t.Errorf("expected missing total to stay nil, got %v", *noTotal.Total)
+	}
+}
@@ -0,0 +1,100 @@
+package protocol
+
+import "encoding/json"
+
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Meta        json.RawMessage  `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	type plain Prompt
+	return unmarshalWithExtra(data, (*plain)(p), &p.Extra)
+}
+
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	type plain Prompt
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (a *PromptArgument) UnmarshalJSON(data []byte) error {
+	type plain PromptArgument
+	return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a PromptArgument) MarshalJSON() ([]byte, error) {
+	type plain PromptArgument
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (m *PromptMessage) UnmarshalJSON(data []byte) error {
+	type plain PromptMessage
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m PromptMessage) MarshalJSON() ([]byte, error) {
+	type plain PromptMessage
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+type ListPromptsResult struct {
+	Prompts    []Prompt        `json:"prompts"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+	Meta       json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ListPromptsResult) UnmarshalJSON(data []byte) error {
+	type plain ListPromptsResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ListPromptsResult) MarshalJSON() ([]byte, error) {
+	type plain ListPromptsResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *GetPromptResult) UnmarshalJSON(data []byte) error {
+	type plain GetPromptResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r GetPromptResult) MarshalJSON() ([]byte, error) {
+	type plain GetPromptResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
@@ -0,0 +1,38 @@
+package protocol
+
+import "testing"
+
+func TestListPromptsResultRoundTrip(t *testing.T) {
+	data := `{"prompts":[{"name":"code_review","title":"Code review","arguments":[{"name":"language","required":true,"x-default":"go"}],"x-category":"dev"}]}`
+
+	var result ListPromptsResult
+	assertRoundTrip(t, data, &result)
+
+	p := result.Prompts[0]
+	if p.Name != "code_review" || len(p.Arguments) != 1 || !p.Arguments[0].Required {
+		t.Errorf("unexpected prompt %+v", p)
+	}
+}
+
+func TestGetPromptResultRoundTrip(t *testing.T) {
+	data := `{
+		"description": "Review code",
+		"messages": [
+			{"role": "user", "content": {"type": "text", "text": "Review this"}},
+			{"role": "assistant", "content": {"type": "resource", "resource": {"uri": "file:///main.go", "text": "package main"}}, "x-step": 2}
+		]
+	}`
+
+	var result GetPromptResult
+	assertRoundTrip(t, data, &result)
+
+	if len(result.Messages) != 2 || result.Messages[0].Role != "user" {
+		t.Fatalf("unexpected messages %+v", result.Messages)
+	}
+	if text, ok := result.Messages[0].Content.ContentBlock.(*TextContent); !ok || text.Text != "Review this" {
+		t.Errorf("unexpected first message content %+v", result.Messages[0].Content)
+	}
+	if result.Messages[1].Content.ContentType() != ContentTypeResource {
+		t.Errorf("expected embedded resource, got %q", result.Messages[1].Content.ContentType())
+	}
+}
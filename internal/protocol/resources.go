@@ -0,0 +1,105 @@
+package protocol
+
+import "encoding/json"
+
+type Resource struct {
+	URI         string          `json:"uri"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Size        *int64          `json:"size,omitempty"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	type plain Resource
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type plain Resource
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type ResourceTemplate struct {
+	URITemplate string          `json:"uriTemplate"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Annotations *Annotations    `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ResourceTemplate) UnmarshalJSON(data []byte) error {
+	type plain ResourceTemplate
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ResourceTemplate) MarshalJSON() ([]byte, error) {
+	type plain ResourceTemplate
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type ListResourcesResult struct {
+	Resources  []Resource      `json:"resources"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+	Meta       json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ListResourcesResult) UnmarshalJSON(data []byte) error {
+	type plain ListResourcesResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ListResourcesResult) MarshalJSON() ([]byte, error) {
+	type plain ListResourcesResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
+	Meta              json.RawMessage    `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ListResourceTemplatesResult) UnmarshalJSON(data []byte) error {
+	type plain ListResourceTemplatesResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ListResourceTemplatesResult) MarshalJSON() ([]byte, error) {
+	type plain ListResourceTemplatesResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+	Meta     json.RawMessage    `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ReadResourceResult) UnmarshalJSON(data []byte) error {
+	type plain ReadResourceResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ReadResourceResult) MarshalJSON() ([]byte, error) {
+	type plain ReadResourceResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
@@ -0,0 +1,52 @@
+package protocol
+
+import "testing"
+
+func TestListResourcesResultRoundTrip(t *testing.T) {
+	data := `{
+		"resources": [{
+			"uri": "file:///project/README.md",
+			"name": "README.md",
+			"title": "Readme",
+			"mimeType": "text/markdown",
+			"size": 1024,
+			"annotations": {"lastModified": "2025-01-12T15:00:58Z", "x-owner": "docs"},
+			"x-tags": ["docs"]
+		}],
+		"nextCursor": "next"
+	}`
+
+	var result ListResourcesResult
+	assertRoundTrip(t, data, &result)
+
+	r := result.Resources[0]
+	if r.URI != "file:///project/README.md" || r.Size == nil || *r.Size != 1024 {
+		t.Errorf("unexpected resource %+v", r)
+	}
+	if r.Annotations == nil || r.Annotations.LastModified != "2025-01-12T15:00:58Z" {
+		t.Errorf("unexpected annotations %+v", r.Annotations)
+	}
+}
+
+func TestListResourceTemplatesResultRoundTrip(t *testing.T) {
+	data := `{"resourceTemplates":[{"uriTemplate":"file:///{path}","name":"files","description":"Project files","x-completion":true}]}`
+
+	var result ListResourceTemplatesResult
+	assertRoundTrip(t, data, &result)
+
+	if result.ResourceTemplates[0].URITemplate != "file:///{path}" {
+		t.Errorf("unexpected template %+v", result.ResourceTemplates[0])
+	}
+}
+
+func TestReadResourceResultRoundTrip(t *testing.T) {
+	data := `{"contents":[{"uri":"file:///a.txt","mimeType":"text/plain","text":"","_meta":{"etag":"1"},"x-encoding":"utf-8"}],"_meta":{"cached":true}}`
+
+	var result ReadResourceResult
+	assertRoundTrip(t, data, &result)
+
+	c := result.Contents[0]
+	if c.Text == nil || *c.Text != "" {
+		t.Errorf("expected empty text to be preserved, got %+v", c)
+	}
+}
@@ -0,0 +1,13 @@
+package protocol
+
+import "encoding/json"
+
+type Root struct {
+	URI  string          `json:"uri"`
+	Name string          `json:"name,omitempty"`
+	Meta json.RawMessage `json:"_meta,omitempty"`
+}
+
+type ListRootsResult struct {
+	Roots []Root `json:"roots"`
+}
@@ -0,0 +1,25 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestListRootsResultJSON(t *testing.T) {
+	data, err := json.Marshal(ListRootsResult{Roots: []Root{
+		{URI: "file:///home/user/project", Name: "project"},
+		{URI: "file:///tmp"},
+	}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{"roots":[{"uri":"file:///home/user/project","name":"project"},{"uri":"file:///tmp"}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	data, _ = json.Marshal(ListRootsResult{Roots: []Root{}})
+	if string(data) != `{"roots":[]}` {
+		t.Errorf("expected empty roots array, got %s", data)
+	}
+}
@@ -0,0 +1,87 @@
+package protocol
+
+import "encoding/json"
+
+type Tool struct {
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	InputSchema  json.RawMessage  `json:"inputSchema,omitempty"`
+	OutputSchema json.RawMessage  `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+	Meta         json.RawMessage  `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (t *Tool) UnmarshalJSON(data []byte) error {
+	type plain Tool
+	return unmarshalWithExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type plain Tool
+	return marshalWithExtra(plain(t), t.Extra)
+}
+
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (a *ToolAnnotations) UnmarshalJSON(data []byte) error {
+	type plain ToolAnnotations
+	return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a ToolAnnotations) MarshalJSON() ([]byte, error) {
+	type plain ToolAnnotations
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+type ListToolsResult struct {
+	Tools      []Tool          `json:"tools"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+	Meta       json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *ListToolsResult) UnmarshalJSON(data []byte) error {
+	type plain ListToolsResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r ListToolsResult) MarshalJSON() ([]byte, error) {
+	type plain ListToolsResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           *bool           `json:"isError,omitempty"`
+	Meta              json.RawMessage `json:"_meta,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *CallToolResult) UnmarshalJSON(data []byte) error {
+	type plain CallToolResult
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+func (r CallToolResult) MarshalJSON() ([]byte, error) {
+	type plain CallToolResult
+	return marshalWithExtra(plain(r), r.Extra)
+}
@@ -0,0 +1,165 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func assertRoundTrip(t *testing.T, data string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var want, got any
+	json.Unmarshal([]byte(data), &want)
+	json.Unmarshal(out, &got)
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Errorf("round trip mismatch:\n  in:  %s\n  out: %s", wantJSON, gotJSON)
+	}
+}
+
+func TestListToolsResultRoundTrip(t *testing.T) {
+	data := `{
+		"tools": [{
+			"name": "get_weather",
+			"title": "Weather",
+			"description": "Current weather",
+			"inputSchema": {"type": "object", "properties": {"city": {"type": "string"}}},
+			"outputSchema": {"type": "object"},
+			"annotations": {"readOnlyHint": true, "openWorldHint": false, "x-cost": 3},
+			"_meta": {"vendor/id": 7},
+			"x-experimental": {"streaming": true}
+		}],
+		"nextCursor": "abc",
+		"_meta": {"page": 1},
+		"x-total": 42
+	}`
+
+	var result ListToolsResult
+	assertRoundTrip(t, data, &result)
+
+	tool := result.Tools[0]
+	if tool.Name != "get_weather" || tool.Title != "Weather" || result.NextCursor != "abc" {
+		t.Errorf("unexpected tool %+v", tool)
+	}
+	if tool.Annotations == nil || tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint {
+		t.Errorf("expected readOnlyHint, got %+v", tool.Annotations)
+	}
+	if tool.Annotations.DestructiveHint != nil {
+		t.Error("expected unset destructiveHint to stay nil")
+	}
+	if string(tool.Extra["x-experimental"]) != `{"streaming": true}` {
+		t.Errorf("expected unknown tool field to be kept, got %s", tool.Extra)
+	}
+	if string(result.Extra["x-total"]) != "42" {
+		t.Errorf("expected unknown result field to be kept, got %s", result.Extra)
+	}
+}
+
+func TestCallToolResultRoundTrip(t *testing.T) {
+	data := `{
+		"content": [
+			{"type": "text", "text": "", "annotations": {"audience": ["user"], "priority": 0.5}},
+			{"type": "image", "data": "aGk=", "mimeType": "image/png"},
+			{"type": "audio", "data": "aGk=", "mimeType": "audio/wav", "x-duration": 1.5},
+			{"type": "resource", "resource": {"uri": "file:///a.txt", "mimeType": "text/plain", "text": "hello"}},
+			{"type": "resource", "resource": {"uri": "file:///b.bin", "blob": "AAE="}},
+			{"type": "resource_link", "uri": "file:///c.txt", "name": "c", "size": 12},
+			{"type": "video", "url": "https://example.com/v.mp4"}
+		],
+		"structuredContent": {"temperature": 21.5},
+		"isError": true,
+		"_meta": {"trace": "t1"}
+	}`
+
+	var result CallToolResult
+	assertRoundTrip(t, data, &result)
+
+	expected := []string{ContentTypeText, ContentTypeImage, ContentTypeAudio, ContentTypeResource, ContentTypeResource, ContentTypeResourceLink, "video"}
+	for i, c := range result.Content {
+		if c.ContentType() != expected[i] {
+			t.Errorf("content %d: expected type %q, got %q", i, expected[i], c.ContentType())
+		}
+	}
+
+	if text, ok := result.Content[0].ContentBlock.(*TextContent); !ok || text.Annotations == nil || *text.Annotations.Priority != 0.5 {
+		t.Errorf("unexpected text content %+v", result.Content[0].ContentBlock)
+	}
+	embedded := result.Content[3].ContentBlock.(*EmbeddedResource)
+	if embedded.Resource.Text == nil || *embedded.Resource.Text != "hello" || embedded.Resource.Blob != nil {
+		t.Errorf("unexpected embedded text resource %+v", embedded.Resource)
+	}
+	blob := result.Content[4].ContentBlock.(*EmbeddedResource)
+	if blob.Resource.Blob == nil || blob.Resource.Text != nil {
+		t.Errorf("unexpected embedded blob resource %+v", blob.Resource)
+	}
+	link := result.Content[5].ContentBlock.(*ResourceLink)
+	if link.URI != "file:///c.txt" || link.Size == nil || *link.Size != 12 {
+		t.Errorf("unexpected resource link %+v", link)
+	}
+}
+
+func TestToolModelsRoundTripOptionalFields(t *testing.T) {
+	var tools ListToolsResult
+	assertRoundTrip(t, `{"tools": [{"name": "ping"}]}`, &tools)
+	if tools.Tools[0].InputSchema != nil {
+		t.Errorf("expected missing inputSchema to stay nil, got %s", tools.Tools[0].InputSchema)
+	}
+
+	var result CallToolResult
+	assertRoundTrip(t, `{
+		"content": [
+			{"type": "image"},
+			{"type": "audio", "data": "", "mimeType": ""}
+		],
+		"isError": false
+	}`, &result)
+	if result.IsError == nil || *result.IsError {
+		t.Errorf("expected explicit isError false, got %v", result.IsError)
+	}
+	image := result.Content[0].ContentBlock.(*ImageContent)
+	if image.Data != nil || image.MimeType != nil {
+		t.Errorf("expected missing image fields to stay nil, got %+v", image)
+	}
+	audio := result.Content[1].ContentBlock.(*AudioContent)
+	if audio.Data == nil || audio.MimeType == nil {
+		t.Errorf("expected empty audio fields to be kept, got %+v", audio)
+	}
+
+	var bare CallToolResult
+	assertRoundTrip(t, `{"content": []}`, &bare)
+	if bare.IsError != nil {
+		t.Errorf("expected missing isError to stay nil, got %v", *bare.IsError)
+	}
+}
+
+func TestContentMarshalAddsType(t *testing.T) {
+	data, err := json.Marshal([]Content{
+		{&TextContent{Text: "hi"}},
+		{&ResourceLink{URI: "file:///a", Name: "a"}},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `[{"type":"text","text":"hi"},{"type":"resource_link","uri":"file:///a","name":"a"}]`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestContentInvalid(t *testing.T) {
+	var c Content
+	if err := json.Unmarshal([]byte(`"text"`), &c); err == nil {
+		t.Error("expected error for non-object content")
+	}
+	if err := json.Unmarshal([]byte(`{"type":"text","text":5}`), &c); err == nil {
+		t.Error("expected error for mistyped text field")
+	}
+}